@@ -4,12 +4,30 @@ package main
 
 import (
 	"log"
+	"os"
+	"strings"
 
 	"port_sentinel/internal/app"
+	"port_sentinel/internal/cli"
 )
 
 func main() {
+	if args := cliArgs(os.Args[1:]); len(args) > 0 {
+		os.Exit(cli.Run(args, os.Stdout, os.Stderr))
+	}
 	if err := app.Run(); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// cliArgs drops the -psn_* argument older macOS versions pass to apps
+// launched from Finder, so they still open the UI.
+func cliArgs(args []string) []string {
+	out := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-psn_") {
+			out = append(out, arg)
+		}
+	}
+	return out
+}
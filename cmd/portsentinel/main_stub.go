@@ -5,9 +5,14 @@ package main
 import (
 	"fmt"
 	"os"
+
+	"port_sentinel/internal/cli"
 )
 
 func main() {
-	fmt.Fprintln(os.Stderr, "Port Sentinel requires CGO-enabled Go toolchain to build the Fyne UI. Set CGO_ENABLED=1 and ensure a working C compiler is installed.")
-	os.Exit(1)
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "This build has no desktop UI (built with CGO_ENABLED=0); use the commands below.")
+		fmt.Fprintln(os.Stderr)
+	}
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
 }
@@ -1,34 +1,81 @@
 package app
 
 import (
+	"context"
 	"errors"
-	"os"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"port_sentinel/internal/ports"
 	"port_sentinel/internal/store"
+	"port_sentinel/internal/util"
 )
 
 type PortScanner interface {
 	ScanPorts(ports []int) ([]ports.PortScanResult, error)
 	ScanPort(port int) (ports.PortScanResult, error)
-	KillPID(pid int, force bool) error
+	ScanAllListeners() ([]ports.PortScanResult, error)
+	ReadEnviron(pid int) ([]ports.EnvVar, error)
+	ReadProcessStat(pid int) (ports.ProcessStat, error)
+	ProcessExists(pid int) bool
+	GetProcessInfo(pid int) (ports.ProcessInfo, error)
+	CaptureLaunchSpec(pid int) (ports.LaunchSpec, error)
+	StartDetached(spec ports.LaunchSpec) (int, error)
+	SignalPID(pid int, sig ports.Signal) error
+	SignalPIDElevated(pid int, sig ports.Signal, method string) error
+	ScanPortsElevated(ports []int, method string) ([]ports.PortScanResult, error)
+	ListProcesses() ([]ports.ProcessEntry, error)
+	CountConnections(port, pid int) (int, error)
+	ProcessManager(pid int, table []ports.ProcessEntry) string
+	ProcessCwd(pid int) (string, error)
+	DirLastModified(dir string) (time.Time, error)
+	RunCommand(argv []string) error
+	BindTest(port int) error
+	ExcludedPortRanges() []ports.PortRange
 }
 
 type ConfigRepository interface {
 	SaveConfig(cfg store.Config) error
+	LoadIntegrity() (map[int]store.ExeRecord, error)
+	SaveIntegrity(records map[int]store.ExeRecord) error
+	LoadBaseline() (store.Baseline, error)
+	SaveBaseline(baseline store.Baseline) error
+	AppendAudit(entry store.AuditEntry) error
+	LoadAudit() ([]store.AuditEntry, error)
 }
 
 type Service struct {
 	state   *State
 	scanner PortScanner
 	repo    ConfigRepository
+
+	mu           sync.Mutex
+	fingerprints map[int]fingerprintEntry
+	certs        map[int]certEntry
+	endpoints    map[int]endpointEntry
+	hashes       map[int]hashEntry
+	integrity    map[int]store.ExeRecord
+	samples      map[int][]ports.ProcessStat
+	remediation  map[remediationKey]*remediationTrack
+
+	introspectors []ports.Introspector
 }
 
 func NewService(state *State, scanner PortScanner, repo ConfigRepository) *Service {
 	return &Service{
-		state:   state,
-		scanner: scanner,
-		repo:    repo,
+		state:        state,
+		scanner:      scanner,
+		repo:         repo,
+		fingerprints: map[int]fingerprintEntry{},
+		certs:        map[int]certEntry{},
+		endpoints:    map[int]endpointEntry{},
+		hashes:       map[int]hashEntry{},
+		samples:      map[int][]ports.ProcessStat{},
+		remediation:  map[remediationKey]*remediationTrack{},
+
+		introspectors: ports.DefaultIntrospectors(),
 	}
 }
 
@@ -39,25 +86,102 @@ func NewDefaultService(state *State) *Service {
 func (s *Service) RefreshAll() ([]ports.PortScanResult, error) {
 	portsList := s.state.GetPorts()
 	results, err := s.scanner.ScanPorts(portsList)
+	s.enrich(results)
 	if len(results) > 0 {
 		s.state.SetResults(results)
 	}
+	if s.state.SnapshotConfig().Baseline.Enabled {
+		if _, baselineErr := s.CheckBaseline(); baselineErr != nil && !errors.Is(baselineErr, store.ErrNoBaseline) && err == nil {
+			err = fmt.Errorf("baseline check: %w", baselineErr)
+		}
+	}
+	s.Remediate(results)
+	return results, err
+}
+
+// RefreshAllElevated scans the watched ports through the elevation helper so
+// listeners owned by other users show up with their PID. The scan is audited.
+func (s *Service) RefreshAllElevated(source string) ([]ports.PortScanResult, error) {
+	cfg := s.state.SnapshotConfig()
+	if !cfg.Elevation.Enabled {
+		return nil, errors.New("elevation is disabled in settings")
+	}
+	results, err := s.scanner.ScanPortsElevated(s.state.GetPorts(), cfg.Elevation.Method)
+	entry := store.AuditEntry{
+		Time:     time.Now().UTC(),
+		User:     currentUser(),
+		Source:   firstNonEmpty(source, SourceGUI),
+		Outcome:  store.AuditOK,
+		Elevated: true,
+		Action:   store.AuditActionListSockets,
+	}
+	if err != nil {
+		entry.Outcome = store.AuditFailed
+		entry.Error = err.Error()
+	}
+	if auditErr := s.repo.AppendAudit(entry); auditErr != nil && err == nil {
+		err = fmt.Errorf("audit log write failed: %w", auditErr)
+	}
+	if len(results) == 0 {
+		return results, err
+	}
+	s.enrich(results)
+	s.state.SetResults(results)
 	return results, err
 }
 
 func (s *Service) RefreshOne(port int) (ports.PortScanResult, error) {
 	res, err := s.scanner.ScanPort(port)
 	if err == nil {
+		single := []ports.PortScanResult{res}
+		s.enrich(single)
+		res = single[0]
 		s.state.SetResult(res)
 	}
 	return res, err
 }
 
-func (s *Service) KillProcess(pid int, force bool) error {
-	if pid == os.Getpid() {
-		return errors.New("refusing to terminate Port Sentinel itself")
+// ScanPorts scans arbitrary ports without adding them to the watched set.
+func (s *Service) ScanPorts(portList []int) ([]ports.PortScanResult, error) {
+	results, err := s.scanner.ScanPorts(portList)
+	s.enrich(results)
+	return results, err
+}
+
+// ScanAllListeners scans every listening socket on the machine.
+func (s *Service) ScanAllListeners() ([]ports.PortScanResult, error) {
+	results, err := s.scanner.ScanAllListeners()
+	s.enrich(results)
+	return results, err
+}
+
+// WaitForPort is ports.WaitForPort with the service's scanner. For
+// ports.WaitHealthy the port's configured health probe is used unless
+// opts.Probe is set.
+func (s *Service) WaitForPort(ctx context.Context, port int, opts ports.WaitOptions) (ports.PortScanResult, error) {
+	opts.Scan = s.scanner.ScanPort
+	if opts.Until == ports.WaitHealthy && opts.Probe == nil {
+		if probe, ok := s.state.SnapshotConfig().HealthProbes[port]; ok {
+			opts.Probe = &probe
+		}
 	}
-	return s.scanner.KillPID(pid, force)
+	return ports.WaitForPort(ctx, port, opts)
+}
+
+// FindFreePorts is ports.FindFreePorts with the service's scanner.
+func (s *Service) FindFreePorts(opts ports.FreePortOptions) ([]int, error) {
+	opts.Scan = s.scanner.ScanPorts
+	opts.Bind = s.scanner.BindTest
+	opts.SystemRanges = s.scanner.ExcludedPortRanges
+	return ports.FindFreePorts(opts)
+}
+
+// AddIntrospector registers an additional dev-endpoint introspector; it is
+// consulted after the built-in ones.
+func (s *Service) AddIntrospector(in ports.Introspector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.introspectors = append(s.introspectors, in)
 }
 
 func (s *Service) SaveConfig() error {
@@ -98,6 +222,26 @@ func (s *Service) TogglePinAndSave(port int, pinned bool) error {
 	return s.SaveConfig()
 }
 
+// SetHealthProbeAndSave configures the probe for port; a nil probe removes it.
+func (s *Service) SetHealthProbeAndSave(port int, probe *ports.HealthProbe) error {
+	if err := ValidatePort(port); err != nil {
+		return err
+	}
+	if probe != nil {
+		if err := probe.Validate(); err != nil {
+			return err
+		}
+	}
+	return s.UpdateUIConfig(func(cfg *store.Config) error {
+		if probe == nil {
+			delete(cfg.HealthProbes, port)
+			return nil
+		}
+		cfg.HealthProbes[port] = *probe
+		return nil
+	})
+}
+
 func ValidatePort(port int) error {
 	if port <= 0 || port > 65535 {
 		return errors.New("port must be 1-65535")
@@ -115,8 +259,79 @@ func (osPortScanner) ScanPort(port int) (ports.PortScanResult, error) {
 	return ports.ScanPort(port)
 }
 
-func (osPortScanner) KillPID(pid int, force bool) error {
-	return ports.KillPID(pid, force)
+func (osPortScanner) ScanAllListeners() ([]ports.PortScanResult, error) {
+	return ports.ScanAllListeners()
+}
+
+func (osPortScanner) ReadEnviron(pid int) ([]ports.EnvVar, error) {
+	return ports.ReadEnviron(pid)
+}
+
+func (osPortScanner) ReadProcessStat(pid int) (ports.ProcessStat, error) {
+	return ports.ReadProcessStat(pid)
+}
+
+func (osPortScanner) GetProcessInfo(pid int) (ports.ProcessInfo, error) {
+	return ports.GetProcessInfo(pid)
+}
+
+func (osPortScanner) CaptureLaunchSpec(pid int) (ports.LaunchSpec, error) {
+	return ports.CaptureLaunchSpec(pid)
+}
+
+func (osPortScanner) StartDetached(spec ports.LaunchSpec) (int, error) {
+	return ports.StartDetached(spec)
+}
+
+func (osPortScanner) ProcessExists(pid int) bool {
+	return ports.ProcessExists(pid)
+}
+
+func (osPortScanner) SignalPIDElevated(pid int, sig ports.Signal, method string) error {
+	return ports.SignalPIDElevated(pid, sig, method)
+}
+
+func (osPortScanner) ScanPortsElevated(portsList []int, method string) ([]ports.PortScanResult, error) {
+	return ports.ScanPortsElevated(portsList, method)
+}
+
+func (osPortScanner) SignalPID(pid int, sig ports.Signal) error {
+	return ports.SignalPID(pid, sig)
+}
+
+func (osPortScanner) ListProcesses() ([]ports.ProcessEntry, error) {
+	return ports.ListProcesses()
+}
+
+func (osPortScanner) CountConnections(port, pid int) (int, error) {
+	return ports.CountConnections(port, pid)
+}
+
+func (osPortScanner) ProcessManager(pid int, table []ports.ProcessEntry) string {
+	return ports.ProcessManager(pid, table)
+}
+
+func (osPortScanner) ProcessCwd(pid int) (string, error) {
+	return ports.ProcessCwd(pid)
+}
+
+func (osPortScanner) DirLastModified(dir string) (time.Time, error) {
+	return ports.DirLastModified(dir)
+}
+
+func (osPortScanner) RunCommand(argv []string) error {
+	if len(argv) == 0 {
+		return errors.New("empty command")
+	}
+	return util.RunCommand(remediationCommandTimeout, argv[0], argv[1:]...).Err
+}
+
+func (osPortScanner) BindTest(port int) error {
+	return ports.BindTest(port)
+}
+
+func (osPortScanner) ExcludedPortRanges() []ports.PortRange {
+	return ports.ExcludedPortRanges()
 }
 
 type fileConfigRepository struct{}
@@ -124,3 +339,36 @@ type fileConfigRepository struct{}
 func (fileConfigRepository) SaveConfig(cfg store.Config) error {
 	return store.SaveConfig(cfg)
 }
+
+func (fileConfigRepository) LoadIntegrity() (map[int]store.ExeRecord, error) {
+	return store.LoadIntegrity()
+}
+
+func (fileConfigRepository) SaveIntegrity(records map[int]store.ExeRecord) error {
+	return store.SaveIntegrity(records)
+}
+
+func (fileConfigRepository) LoadBaseline() (store.Baseline, error) {
+	return store.LoadBaseline()
+}
+
+func (fileConfigRepository) AppendAudit(entry store.AuditEntry) error {
+	return store.AppendAudit(entry)
+}
+
+func (fileConfigRepository) LoadAudit() ([]store.AuditEntry, error) {
+	return store.LoadAudit()
+}
+
+func (fileConfigRepository) SaveBaseline(baseline store.Baseline) error {
+	return store.SaveBaseline(baseline)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, val := range values {
+		if strings.TrimSpace(val) != "" {
+			return val
+		}
+	}
+	return ""
+}
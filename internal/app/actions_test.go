@@ -1,70 +1,424 @@
 package app
 
 import (
+	"errors"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"port_sentinel/internal/ports"
 	"port_sentinel/internal/store"
 )
 
+// TestMain pins the current user so kill policy decisions do not depend on
+// who runs the tests (root would trip ProtectRoot).
+func TestMain(m *testing.M) {
+	currentUserOnce.Do(func() {})
+	currentUserName = "dev"
+	os.Exit(m.Run())
+}
+
 type fakeScanner struct {
 	killedPID   int
-	killedForce bool
 	killErr     error
+	signals     []ports.Signal
+	alive       int
+	portResults []ports.PortScanResult
+	info        ports.ProcessInfo
+	spec        ports.LaunchSpec
+	started     []ports.LaunchSpec
+	elevated    int
+	scanResults []ports.PortScanResult
+	listeners   []ports.PortScanResult
+	environ     []ports.EnvVar
+	stats       map[int][]ports.ProcessStat
+	processes   []ports.ProcessEntry
+	connections int
+	manager     string
+	cwd         map[int]string
+	modified    map[string]time.Time
+	commands    [][]string
+	unbindable  map[int]bool
+	excluded    []ports.PortRange
 }
 
 func (f *fakeScanner) ScanPorts(_ []int) ([]ports.PortScanResult, error) {
-	return nil, nil
+	return f.scanResults, nil
 }
 
+// ScanPort returns portResults in order, repeating the last one.
 func (f *fakeScanner) ScanPort(_ int) (ports.PortScanResult, error) {
-	return ports.PortScanResult{}, nil
+	if len(f.portResults) == 0 {
+		return ports.PortScanResult{}, nil
+	}
+	res := f.portResults[0]
+	if len(f.portResults) > 1 {
+		f.portResults = f.portResults[1:]
+	}
+	return res, nil
+}
+
+func (f *fakeScanner) CaptureLaunchSpec(_ int) (ports.LaunchSpec, error) {
+	return f.spec, nil
+}
+
+func (f *fakeScanner) StartDetached(spec ports.LaunchSpec) (int, error) {
+	f.started = append(f.started, spec)
+	return 5000 + len(f.started), nil
+}
+
+func (f *fakeScanner) GetProcessInfo(pid int) (ports.ProcessInfo, error) {
+	info := f.info
+	info.PID = pid
+	return info, nil
+}
+
+func (f *fakeScanner) ScanAllListeners() ([]ports.PortScanResult, error) {
+	return f.listeners, nil
+}
+
+func (f *fakeScanner) ReadEnviron(pid int) ([]ports.EnvVar, error) {
+	return f.environ, nil
+}
+
+func (f *fakeScanner) ReadProcessStat(pid int) (ports.ProcessStat, error) {
+	queue := f.stats[pid]
+	if len(queue) == 0 {
+		return ports.ProcessStat{}, errors.New("no such process")
+	}
+	f.stats[pid] = queue[1:]
+	return queue[0], nil
 }
 
-func (f *fakeScanner) KillPID(pid int, force bool) error {
+func (f *fakeScanner) SignalPID(pid int, sig ports.Signal) error {
 	f.killedPID = pid
-	f.killedForce = force
+	f.signals = append(f.signals, sig)
 	return f.killErr
 }
 
+func (f *fakeScanner) SignalPIDElevated(pid int, sig ports.Signal, _ string) error {
+	f.elevated++
+	return f.SignalPID(pid, sig)
+}
+
+func (f *fakeScanner) ScanPortsElevated(portsList []int, _ string) ([]ports.PortScanResult, error) {
+	f.elevated++
+	return f.ScanPorts(portsList)
+}
+
+func (f *fakeScanner) ListProcesses() ([]ports.ProcessEntry, error) {
+	return f.processes, nil
+}
+
+func (f *fakeScanner) CountConnections(_, _ int) (int, error) {
+	return f.connections, nil
+}
+
+func (f *fakeScanner) ProcessManager(_ int, _ []ports.ProcessEntry) string {
+	return f.manager
+}
+
+func (f *fakeScanner) ProcessCwd(pid int) (string, error) {
+	return f.cwd[pid], nil
+}
+
+func (f *fakeScanner) DirLastModified(dir string) (time.Time, error) {
+	if t, ok := f.modified[dir]; ok {
+		return t, nil
+	}
+	return time.Time{}, os.ErrNotExist
+}
+
+func (f *fakeScanner) RunCommand(argv []string) error {
+	f.commands = append(f.commands, argv)
+	return nil
+}
+
+func (f *fakeScanner) BindTest(port int) error {
+	if f.unbindable[port] {
+		return errors.New("address already in use")
+	}
+	return nil
+}
+
+func (f *fakeScanner) ExcludedPortRanges() []ports.PortRange {
+	return f.excluded
+}
+
+// ProcessExists reports the process alive for the first alive calls.
+func (f *fakeScanner) ProcessExists(_ int) bool {
+	if f.alive > 0 {
+		f.alive--
+		return true
+	}
+	return false
+}
+
 type fakeRepo struct{}
 
 func (fakeRepo) SaveConfig(_ store.Config) error {
 	return nil
 }
 
+func (fakeRepo) LoadIntegrity() (map[int]store.ExeRecord, error) {
+	return map[int]store.ExeRecord{}, nil
+}
+
+func (fakeRepo) SaveIntegrity(_ map[int]store.ExeRecord) error {
+	return nil
+}
+
+func (fakeRepo) LoadBaseline() (store.Baseline, error) {
+	return store.Baseline{}, store.ErrNoBaseline
+}
+
+func (fakeRepo) SaveBaseline(_ store.Baseline) error {
+	return nil
+}
+
+func (fakeRepo) AppendAudit(_ store.AuditEntry) error {
+	return nil
+}
+
+func (fakeRepo) LoadAudit() ([]store.AuditEntry, error) {
+	return nil, nil
+}
+
 func TestServiceKillProcessRejectsSelf(t *testing.T) {
-	state := NewState(store.DefaultConfig())
 	scanner := &fakeScanner{}
-	svc := NewService(state, scanner, fakeRepo{})
+	svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
 
-	err := svc.KillProcess(os.Getpid(), true)
-	if err == nil {
-		t.Fatalf("expected self-kill to be rejected")
-	}
-	if !strings.Contains(err.Error(), "refusing") {
-		t.Fatalf("expected refusing error, got: %v", err)
+	_, err := svc.KillProcess(ports.PortScanResult{Port: 3000, PID: os.Getpid()}, KillOptions{Signal: ports.SignalKILL})
+	if err == nil || !strings.Contains(err.Error(), "refusing") {
+		t.Fatalf("expected self-kill to be rejected, got: %v", err)
 	}
 	if scanner.killedPID != 0 {
 		t.Fatalf("expected scanner not to be called, got pid=%d", scanner.killedPID)
 	}
 }
 
-func TestServiceKillProcessDelegatesToScanner(t *testing.T) {
-	state := NewState(store.DefaultConfig())
-	scanner := &fakeScanner{}
-	svc := NewService(state, scanner, fakeRepo{})
-
+func TestServiceKillProcessReportsExitAndFreePort(t *testing.T) {
 	targetPID := os.Getpid() + 1000
-	if err := svc.KillProcess(targetPID, true); err != nil {
-		t.Fatalf("expected delegate kill to pass, got: %v", err)
+	scanner := &fakeScanner{alive: 3, portResults: []ports.PortScanResult{
+		{Port: 3000, Status: ports.StatusInUse, PID: targetPID},
+		{Port: 3000, Status: ports.StatusFree},
+	}}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
+
+	report, err := svc.KillProcess(ports.PortScanResult{Port: 3000, PID: targetPID, User: currentUser()}, KillOptions{GracePeriod: time.Second})
+	if err != nil {
+		t.Fatalf("expected terminate to succeed, got: %v", err)
+	}
+	if scanner.killedPID != targetPID || len(scanner.signals) != 1 || scanner.signals[0] != ports.SignalTERM {
+		t.Fatalf("expected a single SIGTERM to %d, got pid=%d signals=%v", targetPID, scanner.killedPID, scanner.signals)
+	}
+	if !report.Exited || report.PortStatus != ports.StatusFree {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	summary := report.Summary()
+	for _, want := range []string{"sent", "exited after", "port 3000 now FREE"} {
+		if !strings.Contains(summary, want) {
+			t.Fatalf("expected %q in summary %q", want, summary)
+		}
+	}
+}
+
+func TestServiceKillProcessEscalates(t *testing.T) {
+	scanner := &fakeScanner{alive: 1 << 30, portResults: []ports.PortScanResult{{Port: 3000, Status: ports.StatusInUse, PID: 4242}}}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
+
+	report, err := svc.KillProcess(ports.PortScanResult{Port: 3000, PID: 4242, User: currentUser()}, KillOptions{GracePeriod: 10 * time.Millisecond, Escalate: true})
+	if err == nil || !strings.Contains(err.Error(), "still running") {
+		t.Fatalf("expected still-running error, got: %v", err)
+	}
+	if len(scanner.signals) != 2 || scanner.signals[0] != ports.SignalTERM || scanner.signals[1] != ports.SignalKILL {
+		t.Fatalf("expected SIGTERM then SIGKILL, got %v", scanner.signals)
+	}
+	if report.Exited || !strings.Contains(report.Summary(), "port 3000 now IN_USE") {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestServiceKillProcessNonTerminatingSignal(t *testing.T) {
+	scanner := &fakeScanner{alive: 1 << 30, portResults: []ports.PortScanResult{{Port: 8080, Status: ports.StatusInUse, PID: 4242}}}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
+
+	report, err := svc.KillProcess(ports.PortScanResult{Port: 8080, PID: 4242, User: currentUser()}, KillOptions{Signal: ports.SignalHUP, GracePeriod: time.Second, Escalate: true})
+	if err != nil {
+		t.Fatalf("expected SIGHUP to succeed without waiting, got: %v", err)
+	}
+	if len(scanner.signals) != 1 || scanner.signals[0] != ports.SignalHUP || report.Exited {
+		t.Fatalf("expected only SIGHUP and a live process, got signals=%v report=%+v", scanner.signals, report)
+	}
+}
+
+func TestServiceRemembersSignalPerClassification(t *testing.T) {
+	svc := NewService(NewState(store.DefaultConfig()), &fakeScanner{}, fakeRepo{})
+	spring := ports.PortScanResult{ProcessName: "java", Classification: &ports.Classification{Label: "Spring Boot"}}
+	other := ports.PortScanResult{ProcessName: "java"}
+
+	if got := svc.PreferredSignal(spring); got != ports.SignalTERM {
+		t.Fatalf("expected SIGTERM by default, got %s", got)
+	}
+	if err := svc.RememberSignal(spring, ports.SignalQUIT); err != nil {
+		t.Fatalf("RememberSignal failed: %v", err)
+	}
+	if got := svc.PreferredSignal(spring); got != ports.SignalQUIT {
+		t.Fatalf("expected remembered SIGQUIT, got %s", got)
+	}
+	if got := svc.PreferredSignal(other); got != ports.SignalTERM {
+		t.Fatalf("expected unclassified java to keep SIGTERM, got %s", got)
+	}
+}
+
+func TestServiceKillProcessRefusesReusedPID(t *testing.T) {
+	started := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	target := ports.PortScanResult{Port: 3000, PID: 4242, ExePath: "/usr/bin/node", StartTime: started, User: currentUser()}
+	cases := map[string]*fakeScanner{
+		"exited": {},
+		"restarted": {alive: 1 << 30, info: ports.ProcessInfo{ExePath: "/usr/bin/node", StartTime: started.Add(time.Minute)},
+			portResults: []ports.PortScanResult{{Port: 3000, Status: ports.StatusInUse, PID: 4242}}},
+		"different exe": {alive: 1 << 30, info: ports.ProcessInfo{ExePath: "/usr/bin/sshd", StartTime: started},
+			portResults: []ports.PortScanResult{{Port: 3000, Status: ports.StatusInUse, PID: 4242}}},
+		"port moved": {alive: 1 << 30, info: ports.ProcessInfo{ExePath: "/usr/bin/node", StartTime: started},
+			portResults: []ports.PortScanResult{{Port: 3000, Status: ports.StatusInUse, PID: 5555}}},
+	}
+	for name, scanner := range cases {
+		svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
+		_, err := svc.KillProcess(target, KillOptions{GracePeriod: time.Millisecond})
+		if !errors.Is(err, ErrProcessChanged) {
+			t.Fatalf("%s: expected ErrProcessChanged, got %v", name, err)
+		}
+		if len(scanner.signals) != 0 {
+			t.Fatalf("%s: expected no signal to be sent, got %v", name, scanner.signals)
+		}
 	}
-	if scanner.killedPID != targetPID {
-		t.Fatalf("expected killed pid %d, got %d", targetPID, scanner.killedPID)
+}
+
+type auditRepo struct {
+	fakeRepo
+	entries []store.AuditEntry
+}
+
+func (r *auditRepo) AppendAudit(entry store.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestServiceKillProcessWritesAudit(t *testing.T) {
+	repo := &auditRepo{}
+	scanner := &fakeScanner{alive: 1, portResults: []ports.PortScanResult{
+		{Port: 3000, Status: ports.StatusInUse, PID: 4242},
+		{Port: 3000, Status: ports.StatusFree},
+	}}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, repo)
+	target := ports.PortScanResult{Port: 3000, PID: 4242, ProcessName: "node", CommandLine: "node server.js --token=abc", User: currentUser()}
+
+	if _, err := svc.KillProcess(target, KillOptions{GracePeriod: time.Second}); err != nil {
+		t.Fatalf("KillProcess failed: %v", err)
+	}
+	if _, err := svc.KillProcess(ports.PortScanResult{Port: 1, PID: 1}, KillOptions{Source: "cli"}); !errors.Is(err, ErrProtected) {
+		t.Fatalf("expected PID 1 to be protected, got %v", err)
+	}
+
+	if len(repo.entries) != 2 {
+		t.Fatalf("expected two audit entries, got %+v", repo.entries)
+	}
+	ok := repo.entries[0]
+	if ok.Outcome != store.AuditOK || !ok.PortFreed || ok.Signal != ports.SignalTERM || ok.Source != SourceGUI {
+		t.Fatalf("unexpected audit entry: %+v", ok)
+	}
+	if strings.Contains(ok.CommandLine, "abc") {
+		t.Fatalf("expected command line to be masked, got %q", ok.CommandLine)
+	}
+	if refused := repo.entries[1]; refused.Outcome != store.AuditRefused || refused.Source != "cli" || refused.Error == "" {
+		t.Fatalf("unexpected refused entry: %+v", refused)
+	}
+}
+
+func TestServiceElevatedKillDoesNotOverrideOwnerProtection(t *testing.T) {
+	cfg := store.DefaultConfig()
+	cfg.Elevation.Enabled = true
+	for _, owner := range []string{"root", "alice", ""} {
+		scanner := &fakeScanner{alive: 1}
+		svc := NewService(NewState(cfg), scanner, fakeRepo{})
+		_, err := svc.KillProcess(ports.PortScanResult{Port: 8080, PID: 4242, User: owner}, KillOptions{Elevated: true, GracePeriod: time.Second})
+		if !errors.Is(err, ErrProtected) {
+			t.Fatalf("owner %q: expected ErrProtected, got %v", owner, err)
+		}
+		if scanner.elevated != 0 || len(scanner.signals) != 0 {
+			t.Fatalf("owner %q: expected no signal, got elevated=%d signals=%v", owner, scanner.elevated, scanner.signals)
+		}
+	}
+}
+
+func TestServiceElevatedKillRequiresOptIn(t *testing.T) {
+	target := ports.PortScanResult{Port: 8080, PID: 4242, User: currentUser()}
+	newScanner := func() *fakeScanner {
+		return &fakeScanner{portResults: []ports.PortScanResult{{Port: 8080, Status: ports.StatusFree}}, alive: 1}
+	}
+
+	scanner := newScanner()
+	repo := &auditRepo{}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, repo)
+	if _, err := svc.KillProcess(target, KillOptions{Elevated: true, GracePeriod: time.Second}); err == nil {
+		t.Fatalf("expected elevated kill to fail while elevation is disabled")
+	}
+	if len(scanner.signals) != 0 {
+		t.Fatalf("expected no signal, got %v", scanner.signals)
+	}
+
+	cfg := store.DefaultConfig()
+	cfg.Elevation.Enabled = true
+	scanner = newScanner()
+	svc = NewService(NewState(cfg), scanner, repo)
+	if _, err := svc.KillProcess(target, KillOptions{Elevated: true, GracePeriod: time.Second}); err != nil {
+		t.Fatalf("elevated kill failed: %v", err)
+	}
+	if scanner.elevated != 1 || len(scanner.signals) != 1 {
+		t.Fatalf("expected one elevated signal, got elevated=%d signals=%v", scanner.elevated, scanner.signals)
+	}
+	if last := repo.entries[len(repo.entries)-1]; !last.Elevated || last.Outcome != store.AuditOK {
+		t.Fatalf("expected elevated audit entry, got %+v", last)
+	}
+}
+
+func TestServiceRefreshAllElevatedIsAudited(t *testing.T) {
+	cfg := store.DefaultConfig()
+	cfg.Elevation.Enabled = true
+	repo := &auditRepo{}
+	scanner := &fakeScanner{scanResults: []ports.PortScanResult{{Port: 3000, Status: ports.StatusInUse, PID: 77}}}
+	svc := NewService(NewState(cfg), scanner, repo)
+
+	results, err := svc.RefreshAllElevated(SourceGUI)
+	if err != nil || len(results) != 1 || scanner.elevated != 1 {
+		t.Fatalf("unexpected elevated refresh: %v %+v (elevated=%d)", err, results, scanner.elevated)
+	}
+	if len(repo.entries) != 1 || repo.entries[0].Action != store.AuditActionListSockets || !repo.entries[0].Elevated {
+		t.Fatalf("expected list-sockets audit entry, got %+v", repo.entries)
+	}
+}
+
+func TestServiceFindFreePortsUsesScanner(t *testing.T) {
+	scanner := &fakeScanner{
+		scanResults: []ports.PortScanResult{
+			{Port: 3000, Status: ports.StatusInUse, PID: 10},
+			{Port: 3001, Status: ports.StatusFree},
+			{Port: 3002, Status: ports.StatusFree},
+			{Port: 3003, Status: ports.StatusFree},
+			{Port: 3004, Status: ports.StatusFree},
+		},
+		unbindable: map[int]bool{3001: true},
+		excluded:   []ports.PortRange{{Min: 3002, Max: 3002}},
+	}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
+
+	got, err := svc.FindFreePorts(ports.FreePortOptions{Near: 3000, Range: ports.PortRange{Min: 3000, Max: 3004}, Count: 2})
+	if err != nil {
+		t.Fatalf("FindFreePorts: %v", err)
 	}
-	if !scanner.killedForce {
-		t.Fatalf("expected force flag to be true")
+	if len(got) != 2 || got[0] != 3003 || got[1] != 3004 {
+		t.Fatalf("expected [3003 3004], got %v", got)
 	}
 }
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+// SnapshotBaseline records every listening socket on the machine as the
+// known-good set and clears outstanding alerts.
+func (s *Service) SnapshotBaseline() (store.Baseline, error) {
+	listeners, err := s.scanner.ScanAllListeners()
+	if err != nil {
+		return store.Baseline{}, err
+	}
+	baseline := store.Baseline{
+		CreatedAt: time.Now().UTC(),
+		Listeners: make([]store.ListenerRecord, 0, len(listeners)),
+	}
+	for _, l := range listeners {
+		baseline.Listeners = append(baseline.Listeners, listenerRecord(l))
+	}
+	if err := s.repo.SaveBaseline(baseline); err != nil {
+		return store.Baseline{}, err
+	}
+	s.state.SetRogueListeners(nil)
+	return baseline, nil
+}
+
+// CheckBaseline scans all listeners and returns the ones that are neither in
+// the baseline nor allowlisted. It returns store.ErrNoBaseline until a
+// snapshot has been taken.
+func (s *Service) CheckBaseline() ([]ports.PortScanResult, error) {
+	baseline, err := s.repo.LoadBaseline()
+	if err != nil {
+		return nil, err
+	}
+	listeners, err := s.scanner.ScanAllListeners()
+	if err != nil {
+		return nil, err
+	}
+	cfg := s.state.SnapshotConfig()
+	newClassifier(cfg.ClassificationRules).apply(listeners)
+	rogue := findRogueListeners(listeners, baseline, cfg.Baseline.Allowlist)
+	s.state.SetRogueListeners(rogue)
+	return rogue, nil
+}
+
+// AllowListener allowlists the exact port/executable/user combination of res.
+func (s *Service) AllowListener(res ports.PortScanResult) error {
+	if res.Port <= 0 {
+		return errors.New("invalid listener")
+	}
+	err := s.UpdateUIConfig(func(cfg *store.Config) error {
+		cfg.Baseline.Allowlist = append(cfg.Baseline.Allowlist, store.ListenerRule{
+			Port: res.Port,
+			Exe:  res.ExePath,
+			User: res.User,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	remaining := make([]ports.PortScanResult, 0)
+	for _, r := range s.state.RogueListeners() {
+		if r.Port != res.Port || r.ExePath != res.ExePath || r.User != res.User {
+			remaining = append(remaining, r)
+		}
+	}
+	s.state.SetRogueListeners(remaining)
+	return nil
+}
+
+func listenerRecord(res ports.PortScanResult) store.ListenerRecord {
+	return store.ListenerRecord{Port: res.Port, Address: res.LocalAddress, PID: res.PID, ExePath: res.ExePath, User: res.User}
+}
+
+// baselineKey is what a listener must share with a baseline record to be
+// known: its socket (port and address) and owner. The PID is left out, as it
+// changes every time the same server restarts.
+type baselineKey struct {
+	port    int
+	address string
+	exe     string
+	user    string
+}
+
+// findRogueListeners checks every socket on its own, so a second listener on
+// a known port, e.g. 0.0.0.0 next to a baselined 127.0.0.1, is reported.
+// Records without an address, from older baselines, match any address.
+func findRogueListeners(listeners []ports.PortScanResult, baseline store.Baseline, allow []store.ListenerRule) []ports.PortScanResult {
+	known := make(map[baselineKey]bool, len(baseline.Listeners))
+	for _, rec := range baseline.Listeners {
+		known[baselineKey{port: rec.Port, address: rec.Address, exe: rec.ExePath, user: rec.User}] = true
+	}
+	type compiledListenerRule struct {
+		rule store.ListenerRule
+		exe  *regexp.Regexp
+	}
+	rules := make([]compiledListenerRule, 0, len(allow))
+	for _, rule := range allow {
+		c := compiledListenerRule{rule: rule}
+		if rule.Exe != "" {
+			re, err := regexp.Compile(globToRegexp(rule.Exe))
+			if err != nil {
+				continue
+			}
+			c.exe = re
+		}
+		rules = append(rules, c)
+	}
+
+	out := make([]ports.PortScanResult, 0)
+	for _, l := range listeners {
+		key := baselineKey{port: l.Port, address: l.LocalAddress, exe: l.ExePath, user: l.User}
+		if known[key] {
+			continue
+		}
+		if key.address = ""; known[key] {
+			continue
+		}
+		allowed := false
+		for _, c := range rules {
+			if c.rule.Port != 0 && c.rule.Port != l.Port {
+				continue
+			}
+			if c.rule.User != "" && c.rule.User != l.User {
+				continue
+			}
+			if c.exe != nil && !c.exe.MatchString(l.ExePath) {
+				continue
+			}
+			allowed = true
+			break
+		}
+		if !allowed {
+			out = append(out, l)
+		}
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"testing"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+func TestFindRogueListeners(t *testing.T) {
+	baseline := store.Baseline{Listeners: []store.ListenerRecord{
+		{Port: 22, ExePath: "/usr/sbin/sshd", User: "root"},
+	}}
+	allow := []store.ListenerRule{
+		{Port: 5432},
+		{Exe: "/home/*/go/bin/*", User: "dev"},
+	}
+	listeners := []ports.PortScanResult{
+		{Port: 22, ExePath: "/usr/sbin/sshd", User: "root"},
+		{Port: 22, ExePath: "/tmp/sshd", User: "root"},
+		{Port: 5432, ExePath: "/usr/lib/postgresql/bin/postgres", User: "postgres"},
+		{Port: 8080, ExePath: "/home/dev/go/bin/api", User: "dev"},
+		{Port: 8081, ExePath: "/home/dev/go/bin/api", User: "root"},
+	}
+
+	rogue := findRogueListeners(listeners, baseline, allow)
+	if len(rogue) != 2 {
+		t.Fatalf("expected 2 rogue listeners, got %+v", rogue)
+	}
+	if rogue[0].ExePath != "/tmp/sshd" || rogue[1].Port != 8081 {
+		t.Fatalf("unexpected rogue listeners: %+v", rogue)
+	}
+}
+
+func TestFindRogueListenersChecksEverySocket(t *testing.T) {
+	baseline := store.Baseline{Listeners: []store.ListenerRecord{
+		{Port: 8080, Address: "127.0.0.1:8080", PID: 100, ExePath: "/usr/bin/api", User: "dev"},
+	}}
+	listeners := []ports.PortScanResult{
+		{Port: 8080, LocalAddress: "127.0.0.1:8080", PID: 200, ExePath: "/usr/bin/api", User: "dev"},
+		{Port: 8080, LocalAddress: "*:8080", PID: 300, ExePath: "/usr/bin/api", User: "dev"},
+	}
+
+	rogue := findRogueListeners(listeners, baseline, nil)
+	if len(rogue) != 1 || rogue[0].PID != 300 {
+		t.Fatalf("expected only the new wildcard socket to be rogue, got %+v", rogue)
+	}
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+type compiledRule struct {
+	field string
+	re    *regexp.Regexp
+	class ports.Classification
+}
+
+type classifier struct {
+	rules []compiledRule
+}
+
+// newClassifier compiles the configured rules; rules with an invalid pattern
+// are skipped so a single typo does not disable classification entirely.
+func newClassifier(rules []store.ClassificationRule) classifier {
+	out := classifier{rules: make([]compiledRule, 0, len(rules))}
+	for _, rule := range rules {
+		if strings.TrimSpace(rule.Pattern) == "" || rule.Label == "" {
+			continue
+		}
+		re, err := compilePattern(rule.Pattern, rule.Regex)
+		if err != nil {
+			continue
+		}
+		out.rules = append(out.rules, compiledRule{field: rule.Field, re: re, class: rule.Classification})
+	}
+	return out
+}
+
+func (c classifier) Classify(res ports.PortScanResult) (ports.Classification, bool) {
+	if res.Status != ports.StatusInUse {
+		return ports.Classification{}, false
+	}
+	for _, rule := range c.rules {
+		value := matchField(res, rule.field)
+		if value != "" && rule.re.MatchString(value) {
+			return rule.class, true
+		}
+	}
+	return ports.Classification{}, false
+}
+
+func (c classifier) apply(results []ports.PortScanResult) {
+	for i := range results {
+		if class, ok := c.Classify(results[i]); ok {
+			results[i].Classification = &class
+		}
+	}
+}
+
+func compilePattern(pattern string, regex bool) (*regexp.Regexp, error) {
+	if !regex {
+		pattern = globToRegexp(pattern)
+	}
+	return regexp.Compile(pattern)
+}
+
+// matchField returns the part of res a rule's Field refers to. Process names
+// are compared without directory or .exe suffix.
+func matchField(res ports.PortScanResult, field string) string {
+	switch field {
+	case store.MatchExePath:
+		return res.ExePath
+	case store.MatchCommandLine:
+		return res.CommandLine
+	default:
+		return strings.TrimSuffix(filepath.Base(strings.ReplaceAll(res.ProcessName, "\\", "/")), ".exe")
+	}
+}
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
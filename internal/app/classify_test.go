@@ -0,0 +1,49 @@
+package app
+
+import (
+	"testing"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+func TestClassifierDefaultRules(t *testing.T) {
+	c := newClassifier(store.DefaultClassificationRules())
+
+	cases := []struct {
+		result ports.PortScanResult
+		label  string
+	}{
+		{ports.PortScanResult{ProcessName: "node", CommandLine: "node /app/node_modules/.bin/next dev"}, "Next.js dev"},
+		{ports.PortScanResult{ProcessName: "node", CommandLine: "node /app/node_modules/.bin/vite --port 5173"}, "Vite dev"},
+		{ports.PortScanResult{ProcessName: "java", CommandLine: "java -cp app.jar org.springframework.boot.loader.JarLauncher"}, "Spring Boot"},
+		{ports.PortScanResult{ProcessName: "python3", CommandLine: "/usr/bin/python3 /usr/local/bin/jupyter-lab"}, "Jupyter"},
+		{ports.PortScanResult{ProcessName: "postgres.exe", CommandLine: `"C:\PostgreSQL\bin\postgres.exe" -D data`}, "PostgreSQL"},
+		{ports.PortScanResult{ProcessName: "mysqld", CommandLine: "/usr/sbin/mysqld"}, "MySQL"},
+	}
+	for _, tc := range cases {
+		tc.result.Status = ports.StatusInUse
+		class, ok := c.Classify(tc.result)
+		if !ok || class.Label != tc.label {
+			t.Fatalf("expected %q for %q, got %+v ok=%v", tc.label, tc.result.CommandLine, class, ok)
+		}
+	}
+
+	if _, ok := c.Classify(ports.PortScanResult{Status: ports.StatusInUse, ProcessName: "node", CommandLine: "node server.js"}); ok {
+		t.Fatalf("expected plain node process to stay unclassified")
+	}
+}
+
+func TestClassifierSkipsInvalidRulesAndHonoursOrder(t *testing.T) {
+	rules := []store.ClassificationRule{
+		{Field: store.MatchProcessName, Pattern: "([", Regex: true, Classification: ports.Classification{Label: "broken"}},
+		{Field: store.MatchExePath, Pattern: "/opt/*/bin/api", Classification: ports.Classification{Label: "Internal API"}},
+		{Field: store.MatchProcessName, Pattern: "API", Classification: ports.Classification{Label: "Generic"}},
+	}
+	c := newClassifier(rules)
+	res := ports.PortScanResult{Status: ports.StatusInUse, ProcessName: "api", ExePath: "/opt/acme/bin/api"}
+	class, ok := c.Classify(res)
+	if !ok || class.Label != "Internal API" {
+		t.Fatalf("expected first valid matching rule to win, got %+v ok=%v", class, ok)
+	}
+}
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+type fingerprintEntry struct {
+	pid int
+	fp  ports.ServiceFingerprint
+}
+
+type certEntry struct {
+	pid       int
+	checkedAt time.Time
+	info      *ports.CertInfo
+	err       string
+}
+
+type endpointEntry struct {
+	pid       int
+	checkedAt time.Time
+	endpoints []ports.Endpoint
+}
+
+// recheckInterval bounds how stale cached certificates and endpoints may get,
+// so a dev server that reloads a renewed cert or a new script is noticed
+// without restarting.
+const recheckInterval = time.Minute
+
+// enrich decorates fresh scan results with everything derived from config:
+// classification labels, health probes and, when enabled, active service
+// fingerprints.
+func (s *Service) enrich(results []ports.PortScanResult) {
+	cfg := s.state.SnapshotConfig()
+	newClassifier(cfg.ClassificationRules).apply(results)
+	if cfg.Fingerprint.Enabled {
+		s.fingerprint(results, time.Duration(cfg.Fingerprint.TimeoutMs)*time.Millisecond)
+	}
+	if len(cfg.HealthProbes) > 0 {
+		runHealthProbes(results, cfg.HealthProbes)
+	}
+	if cfg.TLSInspection.Enabled {
+		s.inspectCerts(results, cfg)
+	}
+	if cfg.Introspection.Enabled {
+		s.introspect(results, time.Duration(cfg.Fingerprint.TimeoutMs)*time.Millisecond)
+	}
+	if cfg.Integrity.Enabled {
+		s.checkIntegrity(results)
+	}
+	if cfg.Resources.Enabled {
+		s.sampleResources(results, cfg.Resources)
+	}
+	if cfg.Orphans.Enabled {
+		s.detectOrphans(results, cfg.Orphans)
+	}
+}
+
+// introspect queries dev endpoints on in-use ports that may speak HTTP.
+func (s *Service) introspect(results []ports.PortScanResult, timeout time.Duration) {
+	s.mu.Lock()
+	introspectors := append([]ports.Introspector(nil), s.introspectors...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := range results {
+		res := &results[i]
+		if res.Status != ports.StatusInUse {
+			continue
+		}
+		if res.Service != nil && res.Service.Protocol != ports.ServiceHTTP {
+			continue
+		}
+		s.mu.Lock()
+		cached, ok := s.endpoints[res.Port]
+		s.mu.Unlock()
+		if ok && cached.pid == res.PID && time.Since(cached.checkedAt) < recheckInterval {
+			res.Endpoints = cached.endpoints
+			continue
+		}
+		wg.Add(1)
+		go func(res *ports.PortScanResult) {
+			defer wg.Done()
+			endpoints := ports.Introspect(ports.DialAddress(res.LocalAddress, res.Port), timeout, introspectors)
+			res.Endpoints = endpoints
+			s.mu.Lock()
+			s.endpoints[res.Port] = endpointEntry{pid: res.PID, checkedAt: time.Now(), endpoints: endpoints}
+			s.mu.Unlock()
+		}(res)
+	}
+	wg.Wait()
+}
+
+func (s *Service) inspectCerts(results []ports.PortScanResult, cfg store.Config) {
+	always := map[int]bool{}
+	for _, port := range cfg.TLSInspection.Ports {
+		always[port] = true
+	}
+	window := time.Duration(cfg.TLSInspection.ExpiryWarnDays) * 24 * time.Hour
+	timeout := time.Duration(cfg.Fingerprint.TimeoutMs) * time.Millisecond
+
+	var wg sync.WaitGroup
+	for i := range results {
+		res := &results[i]
+		if res.Status != ports.StatusInUse {
+			continue
+		}
+		speaksTLS := always[res.Port] ||
+			(res.Service != nil && res.Service.Protocol == ports.ServiceTLS) ||
+			cfg.HealthProbes[res.Port].Type == ports.ProbeHTTPS
+		if !speaksTLS {
+			continue
+		}
+		wg.Add(1)
+		go func(res *ports.PortScanResult) {
+			defer wg.Done()
+			entry := s.certFor(res, timeout)
+			if entry.info == nil {
+				res.Warnings = append(res.Warnings, "TLS inspection failed: "+entry.err)
+				return
+			}
+			res.TLS = entry.info
+			res.Warnings = append(res.Warnings, entry.info.Warnings(time.Now(), window)...)
+		}(res)
+	}
+	wg.Wait()
+}
+
+func (s *Service) certFor(res *ports.PortScanResult, timeout time.Duration) certEntry {
+	s.mu.Lock()
+	cached, ok := s.certs[res.Port]
+	s.mu.Unlock()
+	if ok && cached.pid == res.PID && time.Since(cached.checkedAt) < recheckInterval {
+		return cached
+	}
+	entry := certEntry{pid: res.PID, checkedAt: time.Now()}
+	info, err := ports.InspectTLS(ports.DialAddress(res.LocalAddress, res.Port), timeout)
+	if err != nil {
+		entry.err = err.Error()
+	} else {
+		entry.info = &info
+	}
+	s.mu.Lock()
+	s.certs[res.Port] = entry
+	s.mu.Unlock()
+	return entry
+}
+
+// runHealthProbes checks every configured port in parallel; each probe is
+// bounded by its own timeout so one hung endpoint cannot stall a refresh.
+func runHealthProbes(results []ports.PortScanResult, probes map[int]ports.HealthProbe) {
+	var wg sync.WaitGroup
+	for i := range results {
+		res := &results[i]
+		probe, ok := probes[res.Port]
+		if !ok {
+			continue
+		}
+		if res.Status == ports.StatusFree {
+			res.Health = &ports.HealthResult{Status: ports.HealthUnhealthy, Detail: "port is not in use"}
+			continue
+		}
+		wg.Add(1)
+		go func(res *ports.PortScanResult, probe ports.HealthProbe) {
+			defer wg.Done()
+			health := ports.RunHealthProbe(ports.DialAddress(res.LocalAddress, res.Port), res.Port, probe)
+			res.Health = &health
+		}(res, probe)
+	}
+	wg.Wait()
+}
+
+// fingerprint probes every in-use port concurrently. A fingerprint is reused
+// for as long as the same PID keeps holding the port, so auto refresh does not
+// hammer local services.
+func (s *Service) fingerprint(results []ports.PortScanResult, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for i := range results {
+		res := &results[i]
+		if res.Status != ports.StatusInUse {
+			continue
+		}
+		s.mu.Lock()
+		cached, ok := s.fingerprints[res.Port]
+		s.mu.Unlock()
+		if ok && cached.pid == res.PID {
+			fp := cached.fp
+			res.Service = &fp
+			continue
+		}
+		wg.Add(1)
+		go func(res *ports.PortScanResult) {
+			defer wg.Done()
+			fp := ports.FingerprintService(ports.DialAddress(res.LocalAddress, res.Port), res.Port, timeout)
+			res.Service = &fp
+			s.mu.Lock()
+			s.fingerprints[res.Port] = fingerprintEntry{pid: res.PID, fp: fp}
+			s.mu.Unlock()
+		}(res)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if warning := serviceMismatch(results[i]); warning != "" {
+			results[i].Warnings = append(results[i].Warnings, warning)
+		}
+	}
+}
+
+func serviceMismatch(res ports.PortScanResult) string {
+	if res.Service == nil {
+		return ""
+	}
+	expected := ports.ExpectedService(res.Port)
+	found := res.Service.Protocol
+	if expected == "" || found == expected {
+		return ""
+	}
+	// HTTPS dev servers on HTTP ports are expected, not suspicious.
+	if expected == ports.ServiceHTTP && found == ports.ServiceTLS {
+		return ""
+	}
+	return fmt.Sprintf("expected %s on port %d but detected %s", expected, res.Port, found)
+}
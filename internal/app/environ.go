@@ -0,0 +1,45 @@
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/util"
+)
+
+type EnvEntry struct {
+	Key         string
+	Value       string
+	Interesting bool
+}
+
+// Environment returns the masked environment of pid with the variables from
+// the configured interesting list first.
+func (s *Service) Environment(pid int) ([]EnvEntry, error) {
+	vars, err := s.scanner.ReadEnviron(pid)
+	if err != nil {
+		return nil, err
+	}
+	cfg := s.state.SnapshotConfig()
+	return maskEnviron(vars, cfg.InterestingEnv), nil
+}
+
+func maskEnviron(vars []ports.EnvVar, interesting []string) []EnvEntry {
+	wanted := make(map[string]bool, len(interesting))
+	for _, key := range interesting {
+		wanted[strings.ToUpper(strings.TrimSpace(key))] = true
+	}
+	out := make([]EnvEntry, 0, len(vars))
+	for _, v := range vars {
+		out = append(out, EnvEntry{
+			Key:         v.Key,
+			Value:       util.MaskEnvValue(v.Key, v.Value),
+			Interesting: wanted[strings.ToUpper(v.Key)],
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Interesting && !out[j].Interesting
+	})
+	return out
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"testing"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+func TestEnvironmentMasksAndHighlights(t *testing.T) {
+	scanner := &fakeScanner{environ: []ports.EnvVar{
+		{Key: "AWS_SECRET_ACCESS_KEY", Value: "abc"},
+		{Key: "HOME", Value: "/home/dev"},
+		{Key: "PORT", Value: "3001"},
+	}}
+	cfg := store.DefaultConfig()
+	cfg.InterestingEnv = []string{"port"}
+	svc := NewService(NewState(cfg), scanner, &fakeRepo{})
+
+	env, err := svc.Environment(42)
+	if err != nil {
+		t.Fatalf("Environment failed: %v", err)
+	}
+	if len(env) != 3 || env[0].Key != "PORT" || !env[0].Interesting {
+		t.Fatalf("expected PORT to be listed first and highlighted, got %+v", env)
+	}
+	for _, e := range env {
+		if e.Key == "AWS_SECRET_ACCESS_KEY" && e.Value != "***" {
+			t.Fatalf("expected secret to be masked, got %+v", e)
+		}
+	}
+}
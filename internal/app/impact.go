@@ -0,0 +1,132 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"port_sentinel/internal/ports"
+)
+
+// KillImpact previews what terminating a process takes down with it. Each
+// part is collected independently; failures are listed in Errors and leave
+// the rest of the preview intact.
+type KillImpact struct {
+	// Children are all descendants, which die with a process-group kill or
+	// lose their parent otherwise.
+	Children []ports.ProcessEntry
+	// OtherPorts are listening ports held by the process or its children
+	// besides the target port.
+	OtherPorts  []int
+	Connections int
+	// Manager names a supervisor such as systemd or Docker; Respawns is set
+	// when there is one, since it may restart the process after the kill.
+	Manager  string
+	Respawns bool
+	Uptime   time.Duration
+	Errors   []string
+}
+
+// KillImpact gathers the preview shown before confirming a kill of target.
+func (s *Service) KillImpact(target ports.PortScanResult) KillImpact {
+	var impact KillImpact
+	if target.PID <= 0 {
+		return impact
+	}
+
+	table, err := s.scanner.ListProcesses()
+	if err != nil {
+		impact.Errors = append(impact.Errors, fmt.Sprintf("process list: %v", err))
+	}
+	impact.Children = ports.Descendants(table, target.PID)
+
+	owners := map[int]bool{target.PID: true}
+	for _, child := range impact.Children {
+		owners[child.PID] = true
+	}
+	listeners, err := s.scanner.ScanAllListeners()
+	if err != nil {
+		impact.Errors = append(impact.Errors, fmt.Sprintf("listeners: %v", err))
+	}
+	seen := map[int]bool{target.Port: true}
+	for _, res := range listeners {
+		if res.Status == ports.StatusInUse && owners[res.PID] && !seen[res.Port] {
+			seen[res.Port] = true
+			impact.OtherPorts = append(impact.OtherPorts, res.Port)
+		}
+	}
+	sort.Ints(impact.OtherPorts)
+
+	if target.Port > 0 {
+		count, err := s.scanner.CountConnections(target.Port, target.PID)
+		if err != nil {
+			impact.Errors = append(impact.Errors, fmt.Sprintf("connections: %v", err))
+		}
+		impact.Connections = count
+	}
+
+	impact.Manager = s.scanner.ProcessManager(target.PID, table)
+	impact.Respawns = impact.Manager != ""
+
+	start := target.StartTime
+	if start.IsZero() {
+		if info, err := s.scanner.GetProcessInfo(target.PID); err == nil {
+			start = info.StartTime
+		}
+	}
+	if !start.IsZero() {
+		impact.Uptime = time.Since(start)
+	}
+	return impact
+}
+
+// Lines renders the preview as short sentences for the kill dialog.
+func (i KillImpact) Lines() []string {
+	lines := make([]string, 0)
+	if i.Uptime > 0 {
+		lines = append(lines, "Uptime: "+formatUptime(i.Uptime))
+	}
+	if len(i.Children) > 0 {
+		names := make([]string, 0, len(i.Children))
+		for _, child := range i.Children {
+			names = append(names, fmt.Sprintf("%s (%d)", child.Name, child.PID))
+		}
+		lines = append(lines, fmt.Sprintf("Child processes: %d — %s", len(i.Children), truncateList(names, 5)))
+	}
+	if len(i.OtherPorts) > 0 {
+		list := make([]string, 0, len(i.OtherPorts))
+		for _, port := range i.OtherPorts {
+			list = append(list, strconv.Itoa(port))
+		}
+		lines = append(lines, "Also listening on: "+truncateList(list, 8))
+	}
+	if i.Connections > 0 {
+		lines = append(lines, fmt.Sprintf("Live connections that will drop: %d", i.Connections))
+	}
+	if i.Respawns {
+		lines = append(lines, fmt.Sprintf("Managed by %s; it may be restarted automatically", i.Manager))
+	}
+	return lines
+}
+
+func truncateList(items []string, max int) string {
+	if len(items) <= max {
+		return strings.Join(items, ", ")
+	}
+	return strings.Join(items[:max], ", ") + fmt.Sprintf(" and %d more", len(items)-max)
+}
+
+func formatUptime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+func TestKillImpact(t *testing.T) {
+	scanner := &fakeScanner{
+		processes: []ports.ProcessEntry{
+			{PID: 1, PPID: 0, Name: "init"},
+			{PID: 100, PPID: 1, Name: "npm"},
+			{PID: 101, PPID: 100, Name: "node"},
+			{PID: 102, PPID: 101, Name: "esbuild"},
+			{PID: 200, PPID: 1, Name: "postgres"},
+		},
+		listeners: []ports.PortScanResult{
+			{Port: 3000, Status: ports.StatusInUse, PID: 101},
+			{Port: 9229, Status: ports.StatusInUse, PID: 101},
+			{Port: 24678, Status: ports.StatusInUse, PID: 102},
+			{Port: 5432, Status: ports.StatusInUse, PID: 200},
+		},
+		connections: 3,
+		manager:     ports.ManagerSystemd,
+	}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
+	target := ports.PortScanResult{Port: 3000, PID: 101, StartTime: time.Now().Add(-2 * time.Hour)}
+
+	impact := svc.KillImpact(target)
+	if len(impact.Children) != 1 || impact.Children[0].PID != 102 {
+		t.Fatalf("expected esbuild as the only child, got %+v", impact.Children)
+	}
+	if len(impact.OtherPorts) != 2 || impact.OtherPorts[0] != 9229 || impact.OtherPorts[1] != 24678 {
+		t.Fatalf("expected other ports 9229 and 24678, got %v", impact.OtherPorts)
+	}
+	if impact.Connections != 3 || !impact.Respawns || impact.Manager != ports.ManagerSystemd {
+		t.Fatalf("unexpected impact %+v", impact)
+	}
+	if impact.Uptime < 2*time.Hour || impact.Uptime > 2*time.Hour+time.Minute {
+		t.Fatalf("expected about 2h uptime, got %s", impact.Uptime)
+	}
+	text := strings.Join(impact.Lines(), "\n")
+	for _, want := range []string{"Uptime: 2h 0m", "esbuild (102)", "9229, 24678", "drop: 3", "Managed by systemd"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in preview:\n%s", want, text)
+		}
+	}
+}
+
+func TestKillImpactFallsBackToProcessInfo(t *testing.T) {
+	scanner := &fakeScanner{info: ports.ProcessInfo{PID: 5, StartTime: time.Now().Add(-90 * time.Second)}}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
+
+	impact := svc.KillImpact(ports.PortScanResult{Port: 8080, PID: 5})
+	if impact.Respawns || len(impact.Children) != 0 || impact.Connections != 0 {
+		t.Fatalf("unexpected impact %+v", impact)
+	}
+	if got := formatUptime(impact.Uptime); got != "1m" {
+		t.Fatalf("expected 1m uptime, got %s", got)
+	}
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+type hashEntry struct {
+	exe string
+	sum string
+}
+
+// checkIntegrity records the SHA-256 of the binary behind each in-use port
+// the first time it is seen and warns when a later holder runs a different
+// binary. Hashes are cached per PID so each process is read once.
+func (s *Service) checkIntegrity(results []ports.PortScanResult) {
+	sums := make(map[int]string, len(results))
+	for i := range results {
+		res := &results[i]
+		if res.Status != ports.StatusInUse || res.PID <= 0 {
+			continue
+		}
+		if sum := s.exeHash(res.PID, res.ExePath); sum != "" {
+			res.ExeSHA256 = sum
+			sums[i] = sum
+		}
+	}
+	if len(sums) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.integrity == nil {
+		records, err := s.repo.LoadIntegrity()
+		if err != nil || records == nil {
+			records = map[int]store.ExeRecord{}
+		}
+		s.integrity = records
+	}
+	changed := false
+	for i, sum := range sums {
+		res := &results[i]
+		rec, ok := s.integrity[res.Port]
+		if !ok {
+			s.integrity[res.Port] = store.ExeRecord{ExePath: res.ExePath, SHA256: sum, FirstSeen: time.Now().UTC()}
+			changed = true
+			continue
+		}
+		if rec.SHA256 != sum {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("binary behind port %d changed since %s (was %s, sha256 %.12s…)",
+				res.Port, rec.FirstSeen.Local().Format("2006-01-02 15:04"), rec.ExePath, rec.SHA256))
+		}
+	}
+	if changed {
+		// Persisting is best effort; the in-memory record still drives warnings.
+		_ = s.repo.SaveIntegrity(cloneIntegrity(s.integrity))
+	}
+}
+
+func (s *Service) exeHash(pid int, exePath string) string {
+	s.mu.Lock()
+	cached, ok := s.hashes[pid]
+	s.mu.Unlock()
+	if ok && cached.exe == exePath {
+		return cached.sum
+	}
+	sum, err := ports.HashExecutable(pid, exePath)
+	if err != nil {
+		return ""
+	}
+	s.mu.Lock()
+	s.hashes[pid] = hashEntry{exe: exePath, sum: sum}
+	s.mu.Unlock()
+	return sum
+}
+
+// TrustExecutable accepts the binary currently holding port as the expected
+// one, clearing the "binary changed" warning.
+func (s *Service) TrustExecutable(port int) error {
+	res, ok := s.state.Result(port)
+	if !ok || res.ExeSHA256 == "" {
+		return errors.New("no executable hash recorded for this port")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.integrity == nil {
+		records, err := s.repo.LoadIntegrity()
+		if err != nil {
+			return err
+		}
+		if records == nil {
+			records = map[int]store.ExeRecord{}
+		}
+		s.integrity = records
+	}
+	s.integrity[port] = store.ExeRecord{ExePath: res.ExePath, SHA256: res.ExeSHA256, FirstSeen: time.Now().UTC()}
+	return s.repo.SaveIntegrity(cloneIntegrity(s.integrity))
+}
+
+func cloneIntegrity(records map[int]store.ExeRecord) map[int]store.ExeRecord {
+	out := make(map[int]store.ExeRecord, len(records))
+	for k, v := range records {
+		out[k] = v
+	}
+	return out
+}
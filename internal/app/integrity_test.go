@@ -0,0 +1,63 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+type memRepo struct {
+	fakeRepo
+	records map[int]store.ExeRecord
+	saves   int
+}
+
+func (m *memRepo) LoadIntegrity() (map[int]store.ExeRecord, error) {
+	return cloneIntegrity(m.records), nil
+}
+
+func (m *memRepo) SaveIntegrity(records map[int]store.ExeRecord) error {
+	m.records = cloneIntegrity(records)
+	m.saves++
+	return nil
+}
+
+func TestCheckIntegrityRecordsThenWarnsOnChange(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	repo := &memRepo{records: map[int]store.ExeRecord{
+		4000: {ExePath: "/usr/bin/old", SHA256: "deadbeef"},
+	}}
+	svc := NewService(NewState(store.DefaultConfig()), &fakeScanner{}, repo)
+
+	results := []ports.PortScanResult{
+		{Port: 3000, Status: ports.StatusInUse, PID: os.Getpid(), ExePath: exe},
+		{Port: 4000, Status: ports.StatusInUse, PID: os.Getpid(), ExePath: exe},
+	}
+	svc.checkIntegrity(results)
+
+	if results[0].ExeSHA256 == "" || len(results[0].Warnings) != 0 {
+		t.Fatalf("expected first sighting to be recorded silently, got %+v", results[0])
+	}
+	if repo.records[3000].SHA256 != results[0].ExeSHA256 || repo.saves != 1 {
+		t.Fatalf("expected new record to be persisted, got %+v (saves=%d)", repo.records, repo.saves)
+	}
+	if len(results[1].Warnings) != 1 || !strings.Contains(results[1].Warnings[0], "changed") {
+		t.Fatalf("expected changed-binary warning, got %+v", results[1].Warnings)
+	}
+
+	svc.state.SetResults(results)
+	if err := svc.TrustExecutable(4000); err != nil {
+		t.Fatalf("TrustExecutable failed: %v", err)
+	}
+	again := []ports.PortScanResult{{Port: 4000, Status: ports.StatusInUse, PID: os.Getpid(), ExePath: exe}}
+	svc.checkIntegrity(again)
+	if len(again[0].Warnings) != 0 {
+		t.Fatalf("expected trusted binary not to warn, got %+v", again[0].Warnings)
+	}
+}
@@ -0,0 +1,294 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+	"port_sentinel/internal/util"
+)
+
+// killPollInterval is how often a terminated process and its port are
+// re-checked while waiting for it to go away.
+const killPollInterval = 100 * time.Millisecond
+
+// Sources of kill requests, recorded in the audit log.
+const (
+	SourceGUI = "gui"
+	SourceCLI = "cli"
+)
+
+type KillOptions struct {
+	// Signal is sent first; it defaults to SIGTERM.
+	Signal ports.Signal
+	// GracePeriod is how long to wait for the process to exit and release
+	// the port after each signal.
+	GracePeriod time.Duration
+	// Escalate sends a force kill when the process outlives GracePeriod.
+	Escalate bool
+	// Source names the entry point, e.g. SourceGUI, for the audit log.
+	Source string
+	// Elevated sends signals through the configured elevation helper. Callers
+	// must have confirmed this with the user separately. It does not override
+	// the kill policy: processes of root, other users or an unknown owner stay
+	// refused unless an allow rule matches.
+	Elevated bool
+	// Policy names the remediation policy behind an automatic kill.
+	Policy string
+}
+
+// ErrProcessChanged means the PID no longer refers to the scanned process or
+// no longer owns the port, so signalling it could hit an unrelated process.
+var ErrProcessChanged = errors.New("process changed since the last scan; refresh and try again")
+
+// KillReport describes what happened during KillProcess, step by step.
+type KillReport struct {
+	PID        int
+	Port       int
+	Signal     ports.Signal
+	Steps      []string
+	Exited     bool
+	Elapsed    time.Duration
+	PortStatus ports.PortStatus
+}
+
+func (r KillReport) Summary() string {
+	return strings.Join(r.Steps, ", ")
+}
+
+// KillProcess signals the process holding target.Port. For terminating
+// signals it waits for the process to exit and the port to be released, and
+// escalates to SIGKILL when allowed. Every attempt, including refused ones, is
+// appended to the audit log; all entry points must kill through here.
+func (s *Service) KillProcess(target ports.PortScanResult, opts KillOptions) (KillReport, error) {
+	report, err := s.killProcess(target, opts)
+	if auditErr := s.repo.AppendAudit(auditEntry(target, opts, report, err)); auditErr != nil {
+		report.Steps = append(report.Steps, fmt.Sprintf("audit log write failed: %v", auditErr))
+	}
+	return report, err
+}
+
+func (s *Service) killProcess(target ports.PortScanResult, opts KillOptions) (KillReport, error) {
+	report := KillReport{PID: target.PID, Port: target.Port, Signal: opts.Signal}
+	if target.PID == os.Getpid() {
+		return report, fmt.Errorf("%w: refusing to terminate Port Sentinel itself", ErrProtected)
+	}
+	if target.PID <= 0 {
+		return report, errors.New("invalid pid")
+	}
+
+	// The policy is checked before any signal, elevated or not.
+	decision := s.KillPolicy(target)
+	if !decision.Allowed {
+		return report, fmt.Errorf("%w: %s", ErrProtected, decision.Reason)
+	}
+	sig := opts.Signal
+	if sig == "" {
+		sig = ports.SignalTERM
+	}
+	if decision.Force && sig.Terminates() {
+		sig = ports.SignalKILL
+		report.Steps = append(report.Steps, decision.Reason)
+	}
+	report.Signal = sig
+	// Without privileges lsof cannot see sockets of other users' processes,
+	// so elevated kills rely on the identity check alone.
+	if err := s.verifyTarget(target, !opts.Elevated); err != nil {
+		return report, err
+	}
+	start := time.Now()
+	if err := s.signal(target.PID, sig, opts); err != nil {
+		return report, fmt.Errorf("%s failed: %w", sig.Label(), err)
+	}
+	report.Steps = append(report.Steps, sig.Label()+" sent")
+	if !sig.Terminates() {
+		report.Exited = !s.scanner.ProcessExists(target.PID)
+		report.Elapsed = time.Since(start)
+		return report, nil
+	}
+	report.Exited = s.waitForRelease(target, opts.GracePeriod)
+
+	if !report.Exited && sig != ports.SignalKILL && opts.Escalate {
+		report.Steps = append(report.Steps, fmt.Sprintf("still running after %s", formatSeconds(time.Since(start))))
+		sig = ports.SignalKILL
+		report.Signal = sig
+		if err := s.verifyTarget(target, false); err != nil {
+			report.Elapsed = time.Since(start)
+			return report, err
+		}
+		if err := s.signal(target.PID, sig, opts); err != nil {
+			report.Elapsed = time.Since(start)
+			return report, fmt.Errorf("%s failed: %w", sig.Label(), err)
+		}
+		report.Steps = append(report.Steps, sig.Label()+" sent")
+		report.Exited = s.waitForRelease(target, opts.GracePeriod)
+	}
+	report.Elapsed = time.Since(start)
+
+	if report.Exited {
+		report.Steps = append(report.Steps, fmt.Sprintf("exited after %s", formatSeconds(report.Elapsed)))
+	}
+	if target.Port > 0 {
+		res, err := s.scanner.ScanPort(target.Port)
+		if err == nil {
+			report.PortStatus = res.Status
+			step := fmt.Sprintf("port %d now %s", target.Port, res.Status)
+			if res.Status == ports.StatusInUse && res.PID > 0 && res.PID != target.PID {
+				step += fmt.Sprintf(" (PID %d)", res.PID)
+			}
+			report.Steps = append(report.Steps, step)
+		}
+	}
+	if !report.Exited {
+		return report, fmt.Errorf("PID %d still running after %s", target.PID, formatSeconds(report.Elapsed))
+	}
+	return report, nil
+}
+
+func (s *Service) signal(pid int, sig ports.Signal, opts KillOptions) error {
+	if !opts.Elevated {
+		return s.scanner.SignalPID(pid, sig)
+	}
+	cfg := s.state.SnapshotConfig()
+	if !cfg.Elevation.Enabled {
+		return errors.New("elevation is disabled in settings")
+	}
+	return s.scanner.SignalPIDElevated(pid, sig, cfg.Elevation.Method)
+}
+
+// verifyTarget re-checks that target.PID is still the scanned process and,
+// when checkPort is set, that it still owns target.Port.
+func (s *Service) verifyTarget(target ports.PortScanResult, checkPort bool) error {
+	if !s.scanner.ProcessExists(target.PID) {
+		return fmt.Errorf("%w: PID %d has already exited", ErrProcessChanged, target.PID)
+	}
+	info, err := s.scanner.GetProcessInfo(target.PID)
+	if err != nil {
+		return fmt.Errorf("cannot verify PID %d: %w", target.PID, err)
+	}
+	if !ports.SameProcess(target, info) {
+		return fmt.Errorf("%w: PID %d is now %s", ErrProcessChanged, target.PID, firstNonEmpty(info.ExePath, info.ProcessName, "a different process"))
+	}
+	if checkPort && target.Port > 0 {
+		res, err := s.scanner.ScanPort(target.Port)
+		if err != nil {
+			return fmt.Errorf("cannot verify port %d: %w", target.Port, err)
+		}
+		if res.Status != ports.StatusInUse || res.PID != target.PID {
+			return fmt.Errorf("%w: PID %d no longer owns port %d", ErrProcessChanged, target.PID, target.Port)
+		}
+	}
+	return nil
+}
+
+// waitForRelease polls until the process is gone and no longer holds the
+// port, or the grace period runs out.
+func (s *Service) waitForRelease(target ports.PortScanResult, grace time.Duration) bool {
+	deadline := time.Now().Add(grace)
+	for {
+		if s.released(target) {
+			return true
+		}
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		time.Sleep(killPollInterval)
+	}
+}
+
+func (s *Service) released(target ports.PortScanResult) bool {
+	if s.scanner.ProcessExists(target.PID) {
+		return false
+	}
+	if target.Port <= 0 {
+		return true
+	}
+	res, err := s.scanner.ScanPort(target.Port)
+	if err != nil {
+		return true
+	}
+	return res.Status != ports.StatusInUse || res.PID != target.PID
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// signalKey identifies which remembered signal applies to a result: its
+// classification label, or the process name when unclassified.
+func signalKey(res ports.PortScanResult) string {
+	if res.Classification != nil && res.Classification.Label != "" {
+		return res.Classification.Label
+	}
+	return res.ProcessName
+}
+
+// PreferredSignal returns the signal last used for this kind of process, or
+// the configured default.
+func (s *Service) PreferredSignal(res ports.PortScanResult) ports.Signal {
+	cfg := s.state.SnapshotConfig()
+	if sig, ok := cfg.UI.LastSignals[signalKey(res)]; ok {
+		if _, valid := ports.ParseSignal(string(sig)); valid {
+			return sig
+		}
+	}
+	if cfg.UI.ForceKillEnabled {
+		return ports.SignalKILL
+	}
+	return ports.SignalTERM
+}
+
+func (s *Service) RememberSignal(res ports.PortScanResult, sig ports.Signal) error {
+	key := signalKey(res)
+	if key == "" {
+		return nil
+	}
+	return s.UpdateUIConfig(func(cfg *store.Config) error {
+		if cfg.UI.LastSignals == nil {
+			cfg.UI.LastSignals = map[string]ports.Signal{}
+		}
+		cfg.UI.LastSignals[key] = sig
+		return nil
+	})
+}
+
+func auditEntry(target ports.PortScanResult, opts KillOptions, report KillReport, err error) store.AuditEntry {
+	entry := store.AuditEntry{
+		Time:        time.Now().UTC(),
+		User:        currentUser(),
+		Source:      firstNonEmpty(opts.Source, SourceGUI),
+		Port:        target.Port,
+		PID:         target.PID,
+		ProcessName: target.ProcessName,
+		CommandLine: util.MaskSensitiveArgs(target.CommandLine),
+		Signal:      report.Signal,
+		Outcome:     store.AuditOK,
+		PortFreed:   report.PortStatus != "" && report.PortStatus != ports.StatusInUse,
+		Elevated:    opts.Elevated,
+		Policy:      opts.Policy,
+		Steps:       report.Steps,
+	}
+	switch {
+	case errors.Is(err, ErrProtected), errors.Is(err, ErrProcessChanged):
+		entry.Outcome = store.AuditRefused
+	case err != nil:
+		entry.Outcome = store.AuditFailed
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// AuditLog returns the retained audit entries, newest first.
+func (s *Service) AuditLog() ([]store.AuditEntry, error) {
+	entries, err := s.repo.LoadAudit()
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, err
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+// detectOrphans flags listeners of the current user that were adopted by
+// init or the session manager, have no terminal, are not supervised and run
+// from a project directory idle for cfg.IdleHours. Without a readable working
+// directory (e.g. on Windows) nothing is flagged.
+func (s *Service) detectOrphans(results []ports.PortScanResult, cfg store.OrphanConfig) {
+	idle := time.Duration(cfg.IdleHours) * time.Hour
+	me := currentUser()
+	var table []ports.ProcessEntry
+	listed := false
+	for i := range results {
+		res := &results[i]
+		if res.Status != ports.StatusInUse || res.PID <= 0 {
+			continue
+		}
+		if res.User != "" && me != "" && !sameUser(res.User, me) {
+			continue
+		}
+		if !listed {
+			table, _ = s.scanner.ListProcesses()
+			listed = true
+		}
+		adopted, ok := ports.ReparentedTo(table, res.PID)
+		if !ok || hasTerminal(table, res.PID) || s.scanner.ProcessManager(res.PID, table) != "" {
+			continue
+		}
+		dir, err := s.scanner.ProcessCwd(res.PID)
+		if err != nil || dir == "" || filepath.Dir(dir) == dir {
+			continue
+		}
+		modified, err := s.scanner.DirLastModified(dir)
+		if err != nil || time.Since(modified) < idle {
+			continue
+		}
+		res.Orphan = &ports.OrphanInfo{AdoptedBy: adopted, ProjectDir: dir, IdleFor: time.Since(modified)}
+	}
+}
+
+func hasTerminal(table []ports.ProcessEntry, pid int) bool {
+	for _, entry := range table {
+		if entry.PID == pid {
+			return entry.TTY != ""
+		}
+	}
+	return false
+}
+
+// Orphans returns the current results flagged as likely orphans.
+func (s *Service) Orphans() []ports.PortScanResult {
+	out := make([]ports.PortScanResult, 0)
+	for _, res := range s.state.SnapshotResults() {
+		if res.Orphan != nil {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+type OrphanCleanup struct {
+	Target ports.PortScanResult
+	Report KillReport
+	Err    error
+}
+
+// CleanupOrphans terminates each target through KillProcess, so the kill
+// policy, identity check and audit log apply to every one. A process holding
+// several ports is signalled once.
+func (s *Service) CleanupOrphans(targets []ports.PortScanResult, opts KillOptions) []OrphanCleanup {
+	out := make([]OrphanCleanup, 0, len(targets))
+	done := map[int]bool{}
+	for _, target := range targets {
+		if done[target.PID] {
+			continue
+		}
+		done[target.PID] = true
+		report, err := s.KillProcess(target, opts)
+		out = append(out, OrphanCleanup{Target: target, Report: report, Err: err})
+	}
+	return out
+}
+
+func describeOrphan(o *ports.OrphanInfo) string {
+	adopted := firstNonEmpty(o.AdoptedBy.Name, "a process that has exited")
+	if o.AdoptedBy.PID > 0 {
+		adopted = fmt.Sprintf("%s (%d)", adopted, o.AdoptedBy.PID)
+	}
+	return fmt.Sprintf("adopted by %s, no terminal, %s untouched for %s", adopted, o.ProjectDir, formatUptime(o.IdleFor))
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+func TestDetectOrphans(t *testing.T) {
+	scanner := &fakeScanner{
+		processes: []ports.ProcessEntry{
+			{PID: 900, PPID: 1, Name: "systemd"},
+			{PID: 1000, PPID: 900, Name: "node"},
+			{PID: 2000, PPID: 1999, TTY: "pts/1", Name: "zsh"},
+			{PID: 2001, PPID: 2000, Name: "vite"},
+			{PID: 3000, PPID: 900, Name: "node"},
+			{PID: 4000, PPID: 1, TTY: "pts/2", Name: "node"},
+			{PID: 5000, PPID: 900, Name: "npm"},
+			{PID: 5001, PPID: 5000, Name: "node"},
+		},
+		cwd: map[int]string{1000: "/home/dev/src/old", 2001: "/home/dev/src/old", 3000: "/home/dev/src/active", 4000: "/home/dev/src/old", 5001: "/home/dev/src/old"},
+		modified: map[string]time.Time{
+			"/home/dev/src/old":    time.Now().Add(-72 * time.Hour),
+			"/home/dev/src/active": time.Now().Add(-time.Hour),
+		},
+	}
+	cfg := store.DefaultConfig()
+	svc := NewService(NewState(cfg), scanner, fakeRepo{})
+	results := []ports.PortScanResult{
+		{Port: 3000, Status: ports.StatusInUse, PID: 1000},
+		{Port: 5173, Status: ports.StatusInUse, PID: 2001},
+		{Port: 4000, Status: ports.StatusInUse, PID: 3000},
+		{Port: 4001, Status: ports.StatusInUse, PID: 4000},
+		{Port: 4002, Status: ports.StatusInUse, PID: 5001},
+	}
+
+	svc.detectOrphans(results, cfg.Orphans)
+	if o := results[0].Orphan; o == nil || o.AdoptedBy.PID != 900 || o.ProjectDir != "/home/dev/src/old" || o.IdleFor < 71*time.Hour {
+		t.Fatalf("expected the adopted idle node to be flagged, got %+v", o)
+	}
+	if got := describeOrphan(results[0].Orphan); got != "adopted by systemd (900), no terminal, /home/dev/src/old untouched for 3d 0h" {
+		t.Fatalf("unexpected description %q", got)
+	}
+	if results[1].Orphan != nil {
+		t.Fatalf("expected a process with a live terminal not to be flagged")
+	}
+	if results[2].Orphan != nil {
+		t.Fatalf("expected a recently touched project not to be flagged")
+	}
+	if results[3].Orphan != nil {
+		t.Fatalf("expected a process with its own terminal not to be flagged")
+	}
+	if results[4].Orphan != nil {
+		t.Fatalf("expected a child of a running launcher not to be flagged")
+	}
+
+	scanner.manager = ports.ManagerSystemd
+	results[0].Orphan = nil
+	svc.detectOrphans(results, cfg.Orphans)
+	if results[0].Orphan != nil {
+		t.Fatalf("expected supervised processes not to be flagged")
+	}
+}
+
+func TestCleanupOrphansKillsEachProcessOnce(t *testing.T) {
+	pid := os.Getpid() + 1000
+	scanner := &fakeScanner{alive: 1, portResults: []ports.PortScanResult{
+		{Port: 3000, Status: ports.StatusInUse, PID: pid},
+		{Port: 3000, Status: ports.StatusFree},
+	}}
+	repo := &auditRepo{}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, repo)
+	orphan := &ports.OrphanInfo{ProjectDir: "/home/dev/src/old"}
+	svc.state.SetResults([]ports.PortScanResult{
+		{Port: 3000, Status: ports.StatusInUse, PID: pid, User: currentUser(), Orphan: orphan},
+		{Port: 9229, Status: ports.StatusInUse, PID: pid, User: currentUser(), Orphan: orphan},
+		{Port: 8080, Status: ports.StatusInUse, PID: pid + 1},
+	})
+
+	targets := svc.Orphans()
+	if len(targets) != 2 {
+		t.Fatalf("expected two orphaned ports, got %+v", targets)
+	}
+	cleaned := svc.CleanupOrphans(targets, KillOptions{GracePeriod: time.Second})
+	if len(cleaned) != 1 || cleaned[0].Err != nil {
+		t.Fatalf("expected one successful cleanup, got %+v", cleaned)
+	}
+	if len(scanner.signals) != 1 || len(repo.entries) != 1 {
+		t.Fatalf("expected one audited signal, got signals=%v audit=%d", scanner.signals, len(repo.entries))
+	}
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+	"runtime"
+	"strings"
+	"sync"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+// ErrProtected means the kill policy forbids terminating the process.
+var ErrProtected = errors.New("protected by kill policy")
+
+// KillDecision is the outcome of evaluating the kill policy for a result.
+// Reason explains a refusal or a forced signal.
+type KillDecision struct {
+	Allowed bool
+	Force   bool
+	Reason  string
+}
+
+var (
+	currentUserOnce sync.Once
+	currentUserName string
+)
+
+func currentUser() string {
+	currentUserOnce.Do(func() {
+		if u, err := user.Current(); err == nil {
+			currentUserName = u.Username
+		}
+	})
+	return currentUserName
+}
+
+// KillPolicy evaluates the configured kill policy for res.
+func (s *Service) KillPolicy(res ports.PortScanResult) KillDecision {
+	cfg := s.state.SnapshotConfig()
+	return evaluateKillPolicy(res, cfg.KillPolicy, currentUser())
+}
+
+func evaluateKillPolicy(res ports.PortScanResult, policy store.KillPolicyConfig, me string) KillDecision {
+	if res.PID == 1 {
+		return KillDecision{Reason: "PID 1 is the init process"}
+	}
+
+	var force, allow *store.KillRule
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+		if !killRuleMatches(*rule, res) {
+			continue
+		}
+		switch rule.Action {
+		case store.KillProtect:
+			return KillDecision{Reason: describeKillRule(*rule, "protected")}
+		case store.KillForce:
+			if force == nil {
+				force = rule
+			}
+		case store.KillAllow:
+			if allow == nil {
+				allow = rule
+			}
+		}
+	}
+
+	if allow == nil && res.User == "" && (policy.ProtectRoot || policy.ProtectOtherUsers) {
+		// Windows reports no owner for SYSTEM and other users' processes,
+		// exactly the ones these protections exist for.
+		return KillDecision{Reason: "owner unknown"}
+	}
+	if allow == nil {
+		if policy.ProtectRoot && isRootUser(res.User) {
+			return KillDecision{Reason: fmt.Sprintf("owned by %s", res.User)}
+		}
+		if policy.ProtectOtherUsers && me != "" && !sameUser(res.User, me) {
+			return KillDecision{Reason: fmt.Sprintf("owned by another user (%s)", res.User)}
+		}
+	}
+
+	decision := KillDecision{Allowed: true}
+	if force != nil {
+		decision.Force = true
+		decision.Reason = describeKillRule(*force, "always force-killed")
+	}
+	return decision
+}
+
+func killRuleMatches(rule store.KillRule, res ports.PortScanResult) bool {
+	if rule.Port == 0 && strings.TrimSpace(rule.Pattern) == "" {
+		return false
+	}
+	if rule.Port != 0 && rule.Port != res.Port {
+		return false
+	}
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return true
+	}
+	re, err := compilePattern(rule.Pattern, rule.Regex)
+	if err != nil {
+		return false
+	}
+	value := matchField(res, rule.Field)
+	return value != "" && re.MatchString(value)
+}
+
+func describeKillRule(rule store.KillRule, verb string) string {
+	var target string
+	switch {
+	case rule.Port != 0 && rule.Pattern != "":
+		target = fmt.Sprintf("%s on port %d", rule.Pattern, rule.Port)
+	case rule.Port != 0:
+		target = fmt.Sprintf("port %d", rule.Port)
+	default:
+		target = rule.Pattern
+	}
+	msg := fmt.Sprintf("%s is %s by policy", target, verb)
+	if rule.Reason != "" {
+		msg += " (" + rule.Reason + ")"
+	}
+	return msg
+}
+
+func isRootUser(name string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(name, `NT AUTHORITY\SYSTEM`)
+	}
+	return name == "root"
+}
+
+// sameUser compares user names, tolerating a missing DOMAIN\ prefix on
+// Windows.
+func sameUser(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	trim := func(name string) string {
+		if i := strings.LastIndex(name, `\`); i >= 0 {
+			return name[i+1:]
+		}
+		return name
+	}
+	return runtime.GOOS == "windows" && strings.EqualFold(trim(a), trim(b))
+}
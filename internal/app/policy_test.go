@@ -0,0 +1,62 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+func TestEvaluateKillPolicy(t *testing.T) {
+	policy := store.DefaultConfig().KillPolicy
+	policy.Rules = append(policy.Rules,
+		store.KillRule{Port: 5432, Action: store.KillProtect, Reason: "shared database"},
+		store.KillRule{Port: 3000, Action: store.KillForce},
+		store.KillRule{Field: store.MatchExePath, Pattern: "/usr/bin/docker-proxy", Action: store.KillAllow},
+	)
+	cases := []struct {
+		name    string
+		res     ports.PortScanResult
+		allowed bool
+		force   bool
+	}{
+		{"init", ports.PortScanResult{PID: 1, ProcessName: "node", User: "dev"}, false, false},
+		{"sshd", ports.PortScanResult{PID: 10, ProcessName: "sshd", User: "dev"}, false, false},
+		{"port rule", ports.PortScanResult{PID: 11, Port: 5432, ProcessName: "postgres", User: "dev"}, false, false},
+		{"root", ports.PortScanResult{PID: 12, Port: 8080, ProcessName: "nginx", User: "root"}, false, false},
+		{"other user", ports.PortScanResult{PID: 13, Port: 8080, ProcessName: "node", User: "alice"}, false, false},
+		{"allowlisted root", ports.PortScanResult{PID: 14, Port: 8080, ExePath: "/usr/bin/docker-proxy", ProcessName: "docker-proxy", User: "root"}, true, false},
+		{"force", ports.PortScanResult{PID: 15, Port: 3000, ProcessName: "node", User: "dev"}, true, true},
+		{"own process", ports.PortScanResult{PID: 16, Port: 8080, ProcessName: "node", User: "dev"}, true, false},
+		{"owner unknown", ports.PortScanResult{PID: 17, Port: 8080, ProcessName: "node"}, false, false},
+		{"allowlisted unknown owner", ports.PortScanResult{PID: 18, Port: 8080, ExePath: "/usr/bin/docker-proxy", ProcessName: "docker-proxy"}, true, false},
+	}
+	for _, tc := range cases {
+		got := evaluateKillPolicy(tc.res, policy, "dev")
+		if tc.name == "owner unknown" && got.Reason != "owner unknown" {
+			t.Fatalf("expected the unknown owner to be named, got %+v", got)
+		}
+		if got.Allowed != tc.allowed || got.Force != tc.force {
+			t.Fatalf("%s: unexpected decision %+v", tc.name, got)
+		}
+		if !got.Allowed && got.Reason == "" {
+			t.Fatalf("%s: expected a reason for refusal", tc.name)
+		}
+	}
+}
+
+func TestServiceKillProcessHonoursPolicy(t *testing.T) {
+	cfg := store.DefaultConfig()
+	cfg.KillPolicy.Rules = []store.KillRule{{Port: 5432, Action: store.KillProtect}}
+	scanner := &fakeScanner{alive: 1 << 30}
+	svc := NewService(NewState(cfg), scanner, fakeRepo{})
+
+	_, err := svc.KillProcess(ports.PortScanResult{Port: 5432, PID: 4242}, KillOptions{})
+	if !errors.Is(err, ErrProtected) {
+		t.Fatalf("expected ErrProtected, got %v", err)
+	}
+	if len(scanner.signals) != 0 {
+		t.Fatalf("expected no signal, got %v", scanner.signals)
+	}
+}
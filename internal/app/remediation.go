@@ -0,0 +1,355 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+	"port_sentinel/internal/util"
+)
+
+// SourcePolicy marks kills and commands started by a remediation policy.
+const SourcePolicy = "policy"
+
+const remediationCommandTimeout = 15 * time.Second
+
+// RemediationEvent is one action a policy took, or would have taken in dry
+// run. Notify events are queued on State for the UI to show.
+type RemediationEvent struct {
+	Time   time.Time
+	Policy string
+	Port   int
+	PID    int
+	Name   string
+	Action string
+	DryRun bool
+	Detail string
+	Err    error
+}
+
+func (e RemediationEvent) String() string {
+	text := fmt.Sprintf("Policy %q: %s", e.Policy, e.Detail)
+	if e.DryRun {
+		text = "[dry run] " + text
+	}
+	if e.Err != nil {
+		text += fmt.Sprintf(" — %v", e.Err)
+	}
+	return text
+}
+
+// remediationKey identifies a policy matching one process on one port; a new
+// PID on the port starts the held-for clock over.
+type remediationKey struct {
+	policy string
+	port   int
+	pid    int
+}
+
+type remediationTrack struct {
+	since time.Time
+	acted bool
+}
+
+// ValidateRemediationPolicy rejects policies that would match every listener
+// or cannot run.
+func ValidateRemediationPolicy(p store.RemediationPolicy) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("a policy needs a name")
+	}
+	if p.Match.Port == 0 && strings.TrimSpace(p.Match.Pattern) == "" {
+		return errors.New("a policy needs a port, a pattern or both")
+	}
+	if p.Match.Port != 0 {
+		if err := ValidatePort(p.Match.Port); err != nil {
+			return err
+		}
+	}
+	if p.Match.Pattern != "" {
+		if _, err := compilePattern(p.Match.Pattern, p.Match.Regex); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if len(p.Actions) == 0 {
+		return errors.New("a policy needs at least one action")
+	}
+	for _, action := range p.Actions {
+		switch action.Type {
+		case store.RemediateNotify:
+		case store.RemediateSignal:
+			if _, ok := ports.ParseSignal(string(action.Signal)); !ok {
+				return fmt.Errorf("unknown signal %q", action.Signal)
+			}
+		case store.RemediateCommand:
+			if len(action.Command) == 0 || strings.TrimSpace(action.Command[0]) == "" {
+				return errors.New("command action needs a command")
+			}
+		default:
+			return fmt.Errorf("unknown action %q", action.Type)
+		}
+	}
+	return nil
+}
+
+// Remediate evaluates the enabled policies against results and runs the
+// actions of every match that has held for long enough. Each match acts once
+// per process; it is re-armed when the process stops matching.
+func (s *Service) Remediate(results []ports.PortScanResult) []RemediationEvent {
+	cfg := s.state.SnapshotConfig()
+	now := time.Now()
+	matched := map[remediationKey]bool{}
+	events := make([]RemediationEvent, 0)
+	facts := &processFacts{scanner: s.scanner, cwd: map[int]string{}}
+
+	for _, policy := range cfg.Remediation.Policies {
+		if !policy.Enabled || ValidateRemediationPolicy(policy) != nil {
+			continue
+		}
+		for _, res := range results {
+			if !remediationMatches(res, policy.Match, facts) {
+				continue
+			}
+			key := remediationKey{policy: policy.Name, port: res.Port, pid: res.PID}
+			matched[key] = true
+			if !s.remediationDue(key, now, time.Duration(policy.Match.MinHeldSec)*time.Second) {
+				continue
+			}
+			dryRun := cfg.Remediation.DryRun || policy.DryRun
+			for _, action := range policy.Actions {
+				events = append(events, s.runRemediation(policy.Name, action, res, dryRun, cfg))
+			}
+		}
+	}
+
+	s.mu.Lock()
+	for key := range s.remediation {
+		if !matched[key] {
+			delete(s.remediation, key)
+		}
+	}
+	s.mu.Unlock()
+
+	notices := make([]RemediationEvent, 0)
+	for _, ev := range events {
+		if ev.Action == store.RemediateNotify || ev.Err != nil {
+			notices = append(notices, ev)
+		}
+	}
+	s.state.AddRemediationEvents(notices)
+	return events
+}
+
+// remediationDue starts the held-for clock on first sight and reports true
+// exactly once, when it has run for minHeld.
+func (s *Service) remediationDue(key remediationKey, now time.Time, minHeld time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	track, ok := s.remediation[key]
+	if !ok {
+		track = &remediationTrack{since: now}
+		s.remediation[key] = track
+	}
+	if track.acted || now.Sub(track.since) < minHeld {
+		return false
+	}
+	track.acted = true
+	return true
+}
+
+func (s *Service) runRemediation(policy string, action store.RemediationAction, res ports.PortScanResult, dryRun bool, cfg store.Config) RemediationEvent {
+	ev := RemediationEvent{
+		Time:   time.Now(),
+		Policy: policy,
+		Port:   res.Port,
+		PID:    res.PID,
+		Name:   res.ProcessName,
+		Action: action.Type,
+		DryRun: dryRun,
+	}
+	who := fmt.Sprintf("PID %d (%s) on port %d", res.PID, firstNonEmpty(res.DisplayName(), res.ProcessName, "unknown"), res.Port)
+
+	switch action.Type {
+	case store.RemediateSignal:
+		ev.Detail = fmt.Sprintf("send %s to %s", action.Signal.Label(), who)
+		if dryRun {
+			break
+		}
+		opts := KillOptions{
+			Signal:      action.Signal,
+			GracePeriod: time.Duration(cfg.UI.KillGracePeriodMs) * time.Millisecond,
+			Escalate:    cfg.UI.KillEscalate,
+			Source:      SourcePolicy,
+			Policy:      policy,
+		}
+		// KillProcess writes its own audit entry.
+		report, err := s.KillProcess(res, opts)
+		ev.Detail = fmt.Sprintf("%s: %s", who, report.Summary())
+		ev.Err = err
+		return ev
+	case store.RemediateCommand:
+		argv := expandRemediationCommand(action.Command, res)
+		ev.Detail = fmt.Sprintf("run %s for %s", util.MaskSensitiveArgs(strings.Join(argv, " ")), who)
+		if !dryRun {
+			ev.Err = s.scanner.RunCommand(argv)
+		}
+	default:
+		ev.Detail = firstNonEmpty(strings.TrimSpace(action.Message), "matched "+who)
+	}
+
+	entry := store.AuditEntry{
+		Time:        ev.Time.UTC(),
+		User:        currentUser(),
+		Source:      SourcePolicy,
+		Port:        res.Port,
+		PID:         res.PID,
+		ProcessName: res.ProcessName,
+		CommandLine: util.MaskSensitiveArgs(res.CommandLine),
+		Signal:      action.Signal,
+		Outcome:     store.AuditOK,
+		Action:      action.Type,
+		Policy:      policy,
+		Steps:       []string{ev.Detail},
+	}
+	switch {
+	case dryRun:
+		entry.Outcome = store.AuditDryRun
+	case ev.Err != nil:
+		entry.Outcome = store.AuditFailed
+		entry.Error = ev.Err.Error()
+	}
+	if err := s.repo.AppendAudit(entry); err != nil && ev.Err == nil {
+		ev.Err = fmt.Errorf("audit log write failed: %w", err)
+	}
+	return ev
+}
+
+// describeRemediationPolicy summarises a policy in one line for the settings
+// dialog.
+func describeRemediationPolicy(p store.RemediationPolicy) string {
+	conds := make([]string, 0)
+	if p.Match.Port != 0 {
+		conds = append(conds, fmt.Sprintf("port %d", p.Match.Port))
+	}
+	if p.Match.Pattern != "" {
+		verb := "is"
+		if p.Match.Negate {
+			verb = "is not"
+		}
+		conds = append(conds, fmt.Sprintf("%s %s %s", firstNonEmpty(p.Match.Field, store.MatchProcessName), verb, p.Match.Pattern))
+	}
+	if p.Match.CwdUnder != "" {
+		conds = append(conds, "cwd under "+p.Match.CwdUnder)
+	}
+	if p.Match.ParentGone {
+		conds = append(conds, "parent gone")
+	}
+	if p.Match.MinHeldSec > 0 {
+		conds = append(conds, fmt.Sprintf("for %ds", p.Match.MinHeldSec))
+	}
+	actions := make([]string, 0, len(p.Actions))
+	for _, action := range p.Actions {
+		switch action.Type {
+		case store.RemediateSignal:
+			actions = append(actions, action.Signal.Label())
+		case store.RemediateCommand:
+			actions = append(actions, "run "+strings.Join(action.Command, " "))
+		default:
+			actions = append(actions, action.Type)
+		}
+	}
+	return fmt.Sprintf("%s: if %s then %s", p.Name, strings.Join(conds, ", "), strings.Join(actions, ", "))
+}
+
+func expandRemediationCommand(command []string, res ports.PortScanResult) []string {
+	replacer := strings.NewReplacer(
+		"{port}", strconv.Itoa(res.Port),
+		"{pid}", strconv.Itoa(res.PID),
+		"{name}", res.ProcessName,
+	)
+	out := make([]string, 0, len(command))
+	for _, arg := range command {
+		out = append(out, replacer.Replace(arg))
+	}
+	return out
+}
+
+// processFacts lazily looks up the process details only some policies need,
+// at most once per evaluation.
+type processFacts struct {
+	scanner PortScanner
+	table   []ports.ProcessEntry
+	listed  bool
+	cwd     map[int]string
+}
+
+func (f *processFacts) workingDir(pid int) string {
+	if dir, ok := f.cwd[pid]; ok {
+		return dir
+	}
+	dir, _ := f.scanner.ProcessCwd(pid)
+	f.cwd[pid] = dir
+	return dir
+}
+
+// parentGone reports whether pid's parent has exited and the process was
+// adopted, by the same rule orphan detection uses (ports.ReparentedTo).
+func (f *processFacts) parentGone(pid int) bool {
+	if !f.listed {
+		f.table, _ = f.scanner.ListProcesses()
+		f.listed = true
+	}
+	_, adopted := ports.ReparentedTo(f.table, pid)
+	return adopted
+}
+
+func remediationMatches(res ports.PortScanResult, match store.RemediationMatch, facts *processFacts) bool {
+	if res.Status != ports.StatusInUse || res.PID <= 0 {
+		return false
+	}
+	if match.Port != 0 && res.Port != match.Port {
+		return false
+	}
+	if match.Pattern != "" {
+		re, err := compilePattern(match.Pattern, match.Regex)
+		if err != nil {
+			return false
+		}
+		// An unreadable value never matches, not even a negated pattern.
+		value := matchField(res, match.Field)
+		if value == "" || re.MatchString(value) == match.Negate {
+			return false
+		}
+	}
+	if match.CwdUnder != "" && !pathUnder(facts.workingDir(res.PID), expandHome(match.CwdUnder)) {
+		return false
+	}
+	if match.ParentGone && !facts.parentGone(res.PID) {
+		return false
+	}
+	return true
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+func pathUnder(path, dir string) bool {
+	if path == "" || dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+func remediationService(scanner *fakeScanner, repo ConfigRepository, policies ...store.RemediationPolicy) *Service {
+	cfg := store.DefaultConfig()
+	cfg.UI.KillGracePeriodMs = 1000
+	cfg.Remediation.Policies = policies
+	return NewService(NewState(cfg), scanner, repo)
+}
+
+func TestRemediateOrphanedDevServer(t *testing.T) {
+	pid := os.Getpid() + 1000
+	scanner := &fakeScanner{
+		alive: 3,
+		portResults: []ports.PortScanResult{
+			{Port: 3000, Status: ports.StatusInUse, PID: pid},
+			{Port: 3000, Status: ports.StatusFree},
+		},
+		processes: []ports.ProcessEntry{{PID: pid, PPID: 1, Name: "node"}},
+		cwd:       map[int]string{pid: "/home/dev/src/app"},
+	}
+	repo := &auditRepo{}
+	svc := remediationService(scanner, repo, store.RemediationPolicy{
+		Name:    "orphaned node",
+		Enabled: true,
+		Match:   store.RemediationMatch{Port: 3000, Field: store.MatchProcessName, Pattern: "node", CwdUnder: "/home/dev/src", ParentGone: true},
+		Actions: []store.RemediationAction{{Type: store.RemediateSignal, Signal: ports.SignalTERM}},
+	})
+	results := []ports.PortScanResult{{Port: 3000, Status: ports.StatusInUse, PID: pid, ProcessName: "node", User: currentUser()}}
+
+	events := svc.Remediate(results)
+	if len(events) != 1 || events[0].Err != nil {
+		t.Fatalf("expected one successful action, got %+v", events)
+	}
+	if len(scanner.signals) != 1 || scanner.signals[0] != ports.SignalTERM {
+		t.Fatalf("expected SIGTERM, got %v", scanner.signals)
+	}
+	if len(repo.entries) != 1 || repo.entries[0].Source != SourcePolicy || repo.entries[0].Policy != "orphaned node" {
+		t.Fatalf("expected the kill to be audited as a policy action, got %+v", repo.entries)
+	}
+	if again := svc.Remediate(results); len(again) != 0 {
+		t.Fatalf("expected a match to act only once, got %+v", again)
+	}
+}
+
+func TestRemediateSkipsWhenConditionsFail(t *testing.T) {
+	scanner := &fakeScanner{
+		processes: []ports.ProcessEntry{{PID: 50, PPID: 1}, {PID: 60, PPID: 50, Name: "node"}},
+		cwd:       map[int]string{60: "/home/dev/src/app"},
+	}
+	svc := remediationService(scanner, fakeRepo{}, store.RemediationPolicy{
+		Name:    "orphaned node",
+		Enabled: true,
+		Match:   store.RemediationMatch{Pattern: "node", CwdUnder: "/home/dev/srcs", ParentGone: false},
+		Actions: []store.RemediationAction{{Type: store.RemediateNotify}},
+	}, store.RemediationPolicy{
+		Name:    "parentless",
+		Enabled: true,
+		Match:   store.RemediationMatch{Pattern: "node", ParentGone: true},
+		Actions: []store.RemediationAction{{Type: store.RemediateNotify}},
+	})
+
+	events := svc.Remediate([]ports.PortScanResult{{Port: 3000, Status: ports.StatusInUse, PID: 60, ProcessName: "node"}})
+	if len(events) != 0 {
+		t.Fatalf("expected no matches, got %+v", events)
+	}
+}
+
+func TestRemediateParentGoneMatchesUserManagerAdoption(t *testing.T) {
+	scanner := &fakeScanner{
+		processes: []ports.ProcessEntry{{PID: 900, PPID: 1, Name: "systemd"}, {PID: 60, PPID: 900, Name: "node"}},
+	}
+	svc := remediationService(scanner, fakeRepo{}, store.RemediationPolicy{
+		Name:    "parentless",
+		Enabled: true,
+		Match:   store.RemediationMatch{Pattern: "node", ParentGone: true},
+		Actions: []store.RemediationAction{{Type: store.RemediateNotify}},
+	})
+
+	events := svc.Remediate([]ports.PortScanResult{{Port: 3000, Status: ports.StatusInUse, PID: 60, ProcessName: "node"}})
+	if len(events) != 1 {
+		t.Fatalf("expected a node adopted by systemd --user to match, got %+v", events)
+	}
+}
+
+func TestRemediateNegatedPatternDryRun(t *testing.T) {
+	scanner := &fakeScanner{alive: 1 << 30}
+	repo := &auditRepo{}
+	svc := remediationService(scanner, repo, store.RemediationPolicy{
+		Name:    "5432 is postgres",
+		Enabled: true,
+		DryRun:  true,
+		Match:   store.RemediationMatch{Port: 5432, Field: store.MatchProcessName, Pattern: "postgres*", Negate: true},
+		Actions: []store.RemediationAction{
+			{Type: store.RemediateNotify},
+			{Type: store.RemediateSignal, Signal: ports.SignalKILL},
+			{Type: store.RemediateCommand, Command: []string{"logger", "port {port} held by {name} ({pid})"}},
+		},
+	})
+
+	if events := svc.Remediate([]ports.PortScanResult{{Port: 5432, Status: ports.StatusInUse, PID: 70, ProcessName: "postgres"}}); len(events) != 0 {
+		t.Fatalf("expected postgres itself not to match, got %+v", events)
+	}
+	events := svc.Remediate([]ports.PortScanResult{{Port: 5432, Status: ports.StatusInUse, PID: 71, ProcessName: "python3"}})
+	if len(events) != 3 {
+		t.Fatalf("expected three dry-run events, got %+v", events)
+	}
+	if len(scanner.signals) != 0 || len(scanner.commands) != 0 {
+		t.Fatalf("expected dry run not to act, got signals=%v commands=%v", scanner.signals, scanner.commands)
+	}
+	for _, entry := range repo.entries {
+		if entry.Outcome != store.AuditDryRun {
+			t.Fatalf("expected dry-run audit entries, got %+v", entry)
+		}
+	}
+	if len(repo.entries) != 3 || !strings.Contains(events[2].Detail, "port 5432 held by python3 (71)") {
+		t.Fatalf("unexpected dry-run output %+v / %+v", repo.entries, events[2])
+	}
+	if queued := svc.state.TakeRemediationEvents(); len(queued) != 1 || queued[0].Action != store.RemediateNotify {
+		t.Fatalf("expected the notify event to be queued, got %+v", queued)
+	}
+}
+
+func TestRemediateWaitsForMinHeld(t *testing.T) {
+	scanner := &fakeScanner{}
+	svc := remediationService(scanner, fakeRepo{}, store.RemediationPolicy{
+		Name:    "slow",
+		Enabled: true,
+		Match:   store.RemediationMatch{Port: 8080, MinHeldSec: 60},
+		Actions: []store.RemediationAction{{Type: store.RemediateCommand, Command: []string{"echo", "{port}"}}},
+	})
+	results := []ports.PortScanResult{{Port: 8080, Status: ports.StatusInUse, PID: 80, ProcessName: "java"}}
+
+	if events := svc.Remediate(results); len(events) != 0 {
+		t.Fatalf("expected no action before the match has held, got %+v", events)
+	}
+	svc.remediation[remediationKey{policy: "slow", port: 8080, pid: 80}].since = time.Now().Add(-time.Minute)
+	if events := svc.Remediate(results); len(events) != 1 {
+		t.Fatalf("expected the command to run once held, got %+v", events)
+	}
+	if len(scanner.commands) != 1 || strings.Join(scanner.commands[0], " ") != "echo 8080" {
+		t.Fatalf("unexpected commands %v", scanner.commands)
+	}
+}
+
+func TestValidateRemediationPolicy(t *testing.T) {
+	valid := store.RemediationPolicy{Name: "p", Match: store.RemediationMatch{Port: 3000}, Actions: []store.RemediationAction{{Type: store.RemediateNotify}}}
+	if err := ValidateRemediationPolicy(valid); err != nil {
+		t.Fatalf("expected valid policy, got %v", err)
+	}
+	catchAll := valid
+	catchAll.Match = store.RemediationMatch{ParentGone: true}
+	if ValidateRemediationPolicy(catchAll) == nil {
+		t.Fatalf("expected a policy without port or pattern to be rejected")
+	}
+	badSignal := valid
+	badSignal.Actions = []store.RemediationAction{{Type: store.RemediateSignal, Signal: "USR9"}}
+	if ValidateRemediationPolicy(badSignal) == nil {
+		t.Fatalf("expected an unknown signal to be rejected")
+	}
+}
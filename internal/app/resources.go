@@ -0,0 +1,124 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+// resourceHistory is how many samples are kept per PID for the trend.
+const resourceHistory = 12
+
+// staleSampleAge drops the history of PIDs that have not been sampled for a
+// while, e.g. after the process exited.
+const staleSampleAge = 10 * time.Minute
+
+// sampleResources records a CPU/RSS reading for every in-use port and
+// attaches the current values and recent history to the result.
+func (s *Service) sampleResources(results []ports.PortScanResult, cfg store.ResourceConfig) {
+	limit := uint64(cfg.MemoryWarnGB * (1 << 30))
+	sampled := make(map[int]bool)
+	for i := range results {
+		res := &results[i]
+		if res.Status != ports.StatusInUse || res.PID <= 0 {
+			continue
+		}
+		s.mu.Lock()
+		history := s.samples[res.PID]
+		s.mu.Unlock()
+		if !sampled[res.PID] {
+			stat, err := s.scanner.ReadProcessStat(res.PID)
+			if err != nil {
+				continue
+			}
+			history = append(history, stat)
+			if len(history) > resourceHistory+1 {
+				history = history[len(history)-resourceHistory-1:]
+			}
+			s.mu.Lock()
+			s.samples[res.PID] = history
+			s.mu.Unlock()
+			sampled[res.PID] = true
+		}
+		res.Resources = resourceUsage(history, limit)
+		if res.Resources != nil && res.Resources.OverLimit {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("RSS %s exceeds the %s limit", formatBytes(res.Resources.RSSBytes), formatBytes(limit)))
+		}
+	}
+
+	s.mu.Lock()
+	for pid, history := range s.samples {
+		if time.Since(history[len(history)-1].At) > staleSampleAge {
+			delete(s.samples, pid)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// resourceUsage turns consecutive samples into CPU percentages. The first
+// sample of a PID has no CPU figure yet.
+func resourceUsage(history []ports.ProcessStat, limit uint64) *ports.ResourceUsage {
+	if len(history) == 0 {
+		return nil
+	}
+	latest := history[len(history)-1]
+	usage := &ports.ResourceUsage{
+		RSSBytes:  latest.RSSBytes,
+		OverLimit: limit > 0 && latest.RSSBytes > limit,
+	}
+	for i, stat := range history {
+		if i > 0 || len(history) == 1 {
+			usage.RSSHistory = append(usage.RSSHistory, stat.RSSBytes)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := history[i-1]
+		wall := stat.At.Sub(prev.At)
+		cpu := 0.0
+		if wall > 0 && stat.CPUTime >= prev.CPUTime {
+			cpu = float64(stat.CPUTime-prev.CPUTime) / float64(wall) * 100
+		}
+		usage.CPUHistory = append(usage.CPUHistory, cpu)
+	}
+	if n := len(usage.CPUHistory); n > 0 {
+		usage.CPUPercent = usage.CPUHistory[n-1]
+	}
+	return usage
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// sparkline renders values as a row of block characters scaled to their max.
+func sparkline(values []float64) string {
+	const blocks = "▁▂▃▄▅▆▇█"
+	levels := []rune(blocks)
+	maxVal := 0.0
+	for _, v := range values {
+		if v > maxVal {
+			maxVal = v
+		}
+	}
+	out := make([]rune, 0, len(values))
+	for _, v := range values {
+		idx := 0
+		if maxVal > 0 {
+			idx = int(v / maxVal * float64(len(levels)-1))
+		}
+		out = append(out, levels[idx])
+	}
+	return string(out)
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+func TestSampleResourcesComputesCPUAndFlagsMemory(t *testing.T) {
+	start := time.Now()
+	scanner := &fakeScanner{stats: map[int][]ports.ProcessStat{
+		42: {
+			{CPUTime: time.Second, RSSBytes: 1 << 30, At: start},
+			{CPUTime: 1500 * time.Millisecond, RSSBytes: 3 << 30, At: start.Add(time.Second)},
+		},
+	}}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, &fakeRepo{})
+	cfg := store.ResourceConfig{Enabled: true, MemoryWarnGB: 2}
+
+	first := []ports.PortScanResult{{Port: 8080, Status: ports.StatusInUse, PID: 42}}
+	svc.sampleResources(first, cfg)
+	if first[0].Resources == nil || first[0].Resources.OverLimit || len(first[0].Resources.CPUHistory) != 0 {
+		t.Fatalf("unexpected first sample: %+v", first[0].Resources)
+	}
+
+	second := []ports.PortScanResult{{Port: 8080, Status: ports.StatusInUse, PID: 42}}
+	svc.sampleResources(second, cfg)
+	usage := second[0].Resources
+	if usage == nil || usage.CPUPercent < 49 || usage.CPUPercent > 51 {
+		t.Fatalf("expected ~50%% CPU, got %+v", usage)
+	}
+	if !usage.OverLimit || len(second[0].Warnings) != 1 || !strings.Contains(second[0].Warnings[0], "3.0 GiB") {
+		t.Fatalf("expected memory warning, got %+v %v", usage, second[0].Warnings)
+	}
+	if len(usage.RSSHistory) != 1 || usage.RSSHistory[0] != 3<<30 {
+		t.Fatalf("unexpected RSS history %v", usage.RSSHistory)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{0, 50, 100}); got != "▁▄█" {
+		t.Fatalf("unexpected sparkline %q", got)
+	}
+	if got := sparkline([]float64{0, 0}); got != "▁▁" {
+		t.Fatalf("unexpected flat sparkline %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"port_sentinel/internal/ports"
+)
+
+// restartWaitTimeout bounds how long RestartProcess waits for the relaunched
+// process to listen on the port again.
+const restartWaitTimeout = 30 * time.Second
+
+const restartPollInterval = 250 * time.Millisecond
+
+// ErrEnvUnreadable refuses a restart that would silently swap the target's
+// environment for Port Sentinel's own.
+var ErrEnvUnreadable = errors.New("environment of the process is not readable")
+
+// RestartOptions controls RestartProcess. InheritEnv allows relaunching with
+// Port Sentinel's own environment when the target's cannot be read.
+type RestartOptions struct {
+	Kill       KillOptions
+	InheritEnv bool
+}
+
+type RestartReport struct {
+	Kill   KillReport
+	Spec   ports.LaunchSpec
+	NewPID int
+	// Back is true once something listens on the port again; HolderPID is
+	// that process, which may be a child of NewPID (e.g. npm → node).
+	Back      bool
+	HolderPID int
+	Steps     []string
+}
+
+func (r RestartReport) Summary() string {
+	return strings.Join(append(append([]string(nil), r.Kill.Steps...), r.Steps...), ", ")
+}
+
+// PreviewRestart captures how target would be relaunched, so the command
+// and a missing environment can be shown before anything is signalled.
+func (s *Service) PreviewRestart(target ports.PortScanResult) (ports.LaunchSpec, error) {
+	spec, err := s.scanner.CaptureLaunchSpec(target.PID)
+	if err != nil {
+		return ports.LaunchSpec{}, fmt.Errorf("cannot capture how PID %d was started: %w", target.PID, err)
+	}
+	return spec, nil
+}
+
+// RestartProcess captures how target was launched, terminates it through
+// KillProcess, waits for the port to free and relaunches it detached with
+// the same argv, working directory and environment. When the environment
+// cannot be read it returns ErrEnvUnreadable before the kill unless
+// opts.InheritEnv is set.
+func (s *Service) RestartProcess(target ports.PortScanResult, opts RestartOptions) (RestartReport, error) {
+	var report RestartReport
+	if sig := opts.Kill.Signal; sig != "" && !sig.Terminates() {
+		return report, fmt.Errorf("%s does not terminate the process", sig.Label())
+	}
+	if decision := s.KillPolicy(target); !decision.Allowed {
+		return report, fmt.Errorf("%w: %s", ErrProtected, decision.Reason)
+	}
+	spec, err := s.PreviewRestart(target)
+	if err != nil {
+		return report, err
+	}
+	report.Spec = spec
+	if spec.Env == nil {
+		if !opts.InheritEnv {
+			return report, fmt.Errorf("%w; relaunching would use Port Sentinel's environment instead", ErrEnvUnreadable)
+		}
+		report.Steps = append(report.Steps, "environment not readable, relaunching with Port Sentinel's")
+	}
+
+	report.Kill, err = s.KillProcess(target, opts.Kill)
+	if err != nil {
+		return report, err
+	}
+	if report.Kill.PortStatus == ports.StatusInUse {
+		return report, fmt.Errorf("port %d was not released", target.Port)
+	}
+
+	report.NewPID, err = s.scanner.StartDetached(spec)
+	if err != nil {
+		return report, fmt.Errorf("relaunch failed: %w", err)
+	}
+	report.Steps = append(report.Steps, fmt.Sprintf("relaunched as PID %d", report.NewPID))
+	if target.Port <= 0 {
+		return report, nil
+	}
+
+	start := time.Now()
+	deadline := start.Add(restartWaitTimeout)
+	for time.Now().Before(deadline) {
+		res, err := s.scanner.ScanPort(target.Port)
+		if err == nil && res.Status == ports.StatusInUse {
+			report.Back = true
+			report.HolderPID = res.PID
+			report.Steps = append(report.Steps, fmt.Sprintf("port %d reclaimed by PID %d after %s", target.Port, res.PID, formatSeconds(time.Since(start))))
+			return report, nil
+		}
+		if !s.scanner.ProcessExists(report.NewPID) {
+			report.Steps = append(report.Steps, fmt.Sprintf("PID %d exited without listening", report.NewPID))
+			return report, errors.New("relaunched process exited before reclaiming the port")
+		}
+		time.Sleep(restartPollInterval)
+	}
+	report.Steps = append(report.Steps, fmt.Sprintf("port %d still free after %s", target.Port, formatSeconds(restartWaitTimeout)))
+	return report, fmt.Errorf("relaunched process did not listen on port %d", target.Port)
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+func TestServiceRestartProcessRelaunchesAndWaitsForPort(t *testing.T) {
+	spec := ports.LaunchSpec{Argv: []string{"node", "server.js"}, Dir: "/srv/app", Env: []string{"PORT=8080"}}
+	scanner := &fakeScanner{spec: spec, portResults: []ports.PortScanResult{
+		{Port: 8080, Status: ports.StatusInUse, PID: 4242},
+		{Port: 8080, Status: ports.StatusFree},
+		{Port: 8080, Status: ports.StatusFree},
+		{Port: 8080, Status: ports.StatusInUse, PID: 5002},
+	}, alive: 1}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
+
+	report, err := svc.RestartProcess(ports.PortScanResult{Port: 8080, PID: 4242, User: currentUser()}, RestartOptions{Kill: KillOptions{GracePeriod: time.Second}})
+	if err != nil {
+		t.Fatalf("RestartProcess failed: %v (%s)", err, report.Summary())
+	}
+	if len(scanner.started) != 1 || !reflect.DeepEqual(scanner.started[0], spec) {
+		t.Fatalf("expected relaunch with captured spec, got %+v", scanner.started)
+	}
+	if !report.Back || report.HolderPID != 5002 || !strings.Contains(report.Summary(), "reclaimed by PID 5002") {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestServiceRestartProcessRejectsNonTerminatingSignal(t *testing.T) {
+	scanner := &fakeScanner{}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
+	if _, err := svc.RestartProcess(ports.PortScanResult{Port: 8080, PID: 4242}, RestartOptions{Kill: KillOptions{Signal: ports.SignalHUP}}); err == nil {
+		t.Fatalf("expected SIGHUP restart to be rejected")
+	}
+	if len(scanner.signals) != 0 || len(scanner.started) != 0 {
+		t.Fatalf("expected nothing to happen, got signals=%v started=%v", scanner.signals, scanner.started)
+	}
+}
+
+func TestServiceRestartProcessNeedsOptInWithoutEnvironment(t *testing.T) {
+	spec := ports.LaunchSpec{Argv: []string{"node", "server.js"}, Dir: "/srv/app"}
+	target := ports.PortScanResult{Port: 8080, PID: 4242, User: currentUser()}
+	scanner := &fakeScanner{spec: spec, portResults: []ports.PortScanResult{
+		{Port: 8080, Status: ports.StatusInUse, PID: 4242},
+		{Port: 8080, Status: ports.StatusFree},
+		{Port: 8080, Status: ports.StatusFree},
+		{Port: 8080, Status: ports.StatusInUse, PID: 5002},
+	}, alive: 1}
+	svc := NewService(NewState(store.DefaultConfig()), scanner, fakeRepo{})
+
+	if _, err := svc.RestartProcess(target, RestartOptions{Kill: KillOptions{GracePeriod: time.Second}}); !errors.Is(err, ErrEnvUnreadable) {
+		t.Fatalf("expected ErrEnvUnreadable, got %v", err)
+	}
+	if len(scanner.signals) != 0 || len(scanner.started) != 0 {
+		t.Fatalf("expected nothing to happen before opting in, got signals=%v started=%v", scanner.signals, scanner.started)
+	}
+
+	report, err := svc.RestartProcess(target, RestartOptions{Kill: KillOptions{GracePeriod: time.Second}, InheritEnv: true})
+	if err != nil {
+		t.Fatalf("RestartProcess failed: %v (%s)", err, report.Summary())
+	}
+	if len(scanner.started) != 1 || !strings.Contains(report.Summary(), "relaunching with Port Sentinel's") {
+		t.Fatalf("expected a relaunch that names the inherited environment, got %+v", report)
+	}
+}
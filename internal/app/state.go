@@ -14,6 +14,8 @@ type State struct {
 	Config  store.Config
 	Ports   []int
 	Results map[int]ports.PortScanResult
+	Rogue   []ports.PortScanResult
+	Events  []RemediationEvent
 }
 
 func NewState(cfg store.Config) *State {
@@ -72,6 +74,13 @@ func (s *State) SetResults(results []ports.PortScanResult) {
 	}
 }
 
+func (s *State) Result(port int) (ports.PortScanResult, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res, ok := s.Results[port]
+	return res, ok
+}
+
 func (s *State) SnapshotResults() []ports.PortScanResult {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -91,6 +100,39 @@ func (s *State) SnapshotResults() []ports.PortScanResult {
 	return out
 }
 
+func (s *State) SetRogueListeners(rogue []ports.PortScanResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Rogue = append([]ports.PortScanResult(nil), rogue...)
+}
+
+func (s *State) RogueListeners() []ports.PortScanResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ports.PortScanResult(nil), s.Rogue...)
+}
+
+// maxQueuedEvents caps the remediation events kept when nobody drains them.
+const maxQueuedEvents = 100
+
+func (s *State) AddRemediationEvents(events []RemediationEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Events = append(s.Events, events...)
+	if extra := len(s.Events) - maxQueuedEvents; extra > 0 {
+		s.Events = append([]RemediationEvent(nil), s.Events[extra:]...)
+	}
+}
+
+// TakeRemediationEvents returns and clears the queued remediation events.
+func (s *State) TakeRemediationEvents() []RemediationEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := s.Events
+	s.Events = nil
+	return events
+}
+
 func (s *State) AddCustomPort(port int) error {
 	if port <= 0 || port > 65535 {
 		return errors.New("port must be 1-65535")
@@ -218,5 +260,34 @@ func cloneConfig(cfg store.Config) store.Config {
 		out.PinnedPorts = map[int]bool{}
 	}
 
+	out.HealthProbes = make(map[int]ports.HealthProbe, len(cfg.HealthProbes))
+	for k, v := range cfg.HealthProbes {
+		v.Command = append([]string(nil), v.Command...)
+		out.HealthProbes[k] = v
+	}
+
+	out.TLSInspection.Ports = append([]int(nil), cfg.TLSInspection.Ports...)
+	out.Baseline.Allowlist = append([]store.ListenerRule(nil), cfg.Baseline.Allowlist...)
+	out.InterestingEnv = append([]string(nil), cfg.InterestingEnv...)
+	out.KillPolicy.Rules = append([]store.KillRule(nil), cfg.KillPolicy.Rules...)
+	out.Remediation.Policies = make([]store.RemediationPolicy, 0, len(cfg.Remediation.Policies))
+	for _, policy := range cfg.Remediation.Policies {
+		actions := make([]store.RemediationAction, 0, len(policy.Actions))
+		for _, action := range policy.Actions {
+			action.Command = append([]string(nil), action.Command...)
+			actions = append(actions, action)
+		}
+		policy.Actions = actions
+		out.Remediation.Policies = append(out.Remediation.Policies, policy)
+	}
+	out.UI.LastSignals = make(map[string]ports.Signal, len(cfg.UI.LastSignals))
+	for k, v := range cfg.UI.LastSignals {
+		out.UI.LastSignals[k] = v
+	}
+
+	if cfg.ClassificationRules != nil {
+		out.ClassificationRules = append([]store.ClassificationRule(nil), cfg.ClassificationRules...)
+	}
+
 	return out
 }
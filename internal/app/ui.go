@@ -3,9 +3,10 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
-	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,6 +14,7 @@ import (
 
 	"port_sentinel/internal/ports"
 	"port_sentinel/internal/store"
+	"port_sentinel/internal/util"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -36,6 +38,7 @@ func Run() error {
 	status.Wrapping = fyne.TextWrapWord
 
 	refresher := &AutoRefresher{}
+	alerts := &rogueNotifier{app: fyneApp, seen: map[store.ListenerRecord]bool{}}
 	var list *widget.List
 
 	refreshAll := func() {
@@ -47,6 +50,7 @@ func Run() error {
 				} else {
 					status.SetText("Refreshed.")
 				}
+				alerts.check(state, status)
 				list.Refresh()
 			})
 		}()
@@ -73,12 +77,17 @@ func Run() error {
 			pin := widget.NewCheck("", nil)
 			status := widget.NewLabel("")
 			pid := widget.NewLabel("")
-			proc := widget.NewLabel("")
+			procDot := canvas.NewText("●", color.Transparent)
+			proc := container.NewHBox(procDot, widget.NewLabel(""))
 			cmd := widget.NewLabel("")
 			updated := widget.NewLabel("")
 			refreshBtn := widget.NewButton("Refresh", nil)
 			killBtn := widget.NewButton("Terminate", nil)
-			actions := container.NewHBox(refreshBtn, killBtn)
+			detailsBtn := widget.NewButton("Details", nil)
+			whyBtn := widget.NewButtonWithIcon("", theme.QuestionIcon(), nil)
+			whyBtn.Hide()
+			restartBtn := widget.NewButton("Restart", nil)
+			actions := container.NewHBox(refreshBtn, killBtn, restartBtn, detailsBtn, whyBtn)
 			grid := container.NewGridWithColumns(8, port, pin, status, pid, proc, cmd, updated, actions)
 			return container.NewMax(bg, grid)
 		},
@@ -97,12 +106,17 @@ func Run() error {
 			pinCheck := grid.Objects[1].(*widget.Check)
 			statusLabel := grid.Objects[2].(*widget.Label)
 			pidLabel := grid.Objects[3].(*widget.Label)
-			procLabel := grid.Objects[4].(*widget.Label)
+			procCell := grid.Objects[4].(*fyne.Container)
+			procDot := procCell.Objects[0].(*canvas.Text)
+			procLabel := procCell.Objects[1].(*widget.Label)
 			cmdLabel := grid.Objects[5].(*widget.Label)
 			updatedLabel := grid.Objects[6].(*widget.Label)
 			actions := grid.Objects[7].(*fyne.Container)
 			refreshBtn := actions.Objects[0].(*widget.Button)
 			killBtn := actions.Objects[1].(*widget.Button)
+			restartBtn := actions.Objects[2].(*widget.Button)
+			detailsBtn := actions.Objects[3].(*widget.Button)
+			whyBtn := actions.Objects[4].(*widget.Button)
 
 			portLabel.SetText(strconv.Itoa(port))
 			pinned := state.IsPinned(port)
@@ -119,20 +133,31 @@ func Run() error {
 				list.UnselectAll()
 				list.Refresh()
 			}
-			if pinned {
+			if result.Resources != nil && result.Resources.OverLimit {
+				bg.FillColor = color.NRGBA{R: 250, G: 222, B: 222, A: 255}
+			} else if pinned {
 				bg.FillColor = color.NRGBA{R: 220, G: 235, B: 250, A: 255}
 			} else {
 				bg.FillColor = color.NRGBA{R: 0, G: 0, B: 0, A: 0}
 			}
 			bg.Refresh()
-			statusLabel.SetText(string(result.Status))
+			statusLabel.SetText(statusText(result))
 			if result.PID > 0 {
 				pidLabel.SetText(strconv.Itoa(result.PID))
 			} else {
 				pidLabel.SetText("-")
 			}
-			procLabel.SetText(ellipsis(result.ProcessName, 18))
-			cmdLabel.SetText(ellipsis(maskSensitiveArgs(firstNonEmpty(result.CommandLine, result.ExePath)), 32))
+			procDot.Color = color.Transparent
+			procText := result.ProcessName
+			if class := result.Classification; class != nil {
+				procText = strings.TrimSpace(class.Icon + " " + class.Label)
+				if c, ok := parseHexColor(class.Color); ok {
+					procDot.Color = c
+				}
+			}
+			procDot.Refresh()
+			procLabel.SetText(ellipsis(procText, 18))
+			cmdLabel.SetText(ellipsis(util.MaskSensitiveArgs(firstNonEmpty(forwardSummary(result), result.CommandLine, result.ExePath)), 32))
 			if !result.UpdatedAt.IsZero() {
 				updatedLabel.SetText(result.UpdatedAt.Local().Format("15:04:05"))
 			} else {
@@ -153,11 +178,29 @@ func Run() error {
 				}()
 			}
 
+			detailsBtn.OnTapped = func() {
+				showDetailsDialog(w, svc, result)
+			}
+
 			killBtn.Disable()
+			restartBtn.Disable()
+			whyBtn.Hide()
 			if result.Status == ports.StatusInUse && result.PID > 0 {
-				killBtn.Enable()
-				killBtn.OnTapped = func() {
-					showKillDialog(fyneApp, w, svc, state, result, status, list)
+				if decision := svc.KillPolicy(result); !decision.Allowed {
+					whyBtn.OnTapped = func() {
+						dialog.ShowInformation("Termination disabled",
+							fmt.Sprintf("PID %d (%s) on port %d cannot be terminated: %s.\n\nChange this under Ports & Settings → Kill Policy.", result.PID, result.DisplayName(), port, decision.Reason), w)
+					}
+					whyBtn.Show()
+				} else {
+					killBtn.Enable()
+					killBtn.OnTapped = func() {
+						showKillDialog(fyneApp, w, svc, state, result, status, list)
+					}
+					restartBtn.Enable()
+					restartBtn.OnTapped = func() {
+						showRestartDialog(w, svc, state, result, status, list)
+					}
 				}
 			}
 		},
@@ -200,7 +243,7 @@ func Run() error {
 			status.SetText(fmt.Sprintf("Auto refresh update failed: %v", err))
 			return
 		}
-		applyAutoRefresh(fyneApp, refresher, svc, intervalSelect.Selected, checked, list, status)
+		applyAutoRefresh(fyneApp, refresher, svc, state, alerts, intervalSelect.Selected, checked, list, status)
 	})
 	autoRefresh.SetChecked(cfg.UI.AutoRefreshEnabled)
 
@@ -212,18 +255,31 @@ func Run() error {
 			status.SetText(fmt.Sprintf("Interval update failed: %v", err))
 			return
 		}
-		applyAutoRefresh(fyneApp, refresher, svc, value, autoRefresh.Checked, list, status)
+		applyAutoRefresh(fyneApp, refresher, svc, state, alerts, value, autoRefresh.Checked, list, status)
 	}
 
+	listenersBtn := widget.NewButton("Listeners", func() {
+		showListenersDialog(w, svc, state, status)
+	})
+	auditBtn := widget.NewButton("Audit Log", func() {
+		showAuditDialog(w, svc)
+	})
+	orphansBtn := widget.NewButton("Clean Up Orphans", func() {
+		showOrphanCleanupDialog(w, svc, state, status, refreshAll)
+	})
+	freePortBtn := widget.NewButton("Free Port", func() {
+		showFreePortDialog(w, svc, strings.TrimSpace(portEntry.Text))
+	})
+
 	settingsBtn := widget.NewButton("Ports & Settings", func() {
 		showSettingsDialog(fyneApp, w, svc, state, list, status)
 	})
 
-	top := container.NewHBox(portEntryWrap, addBtn, refreshAllBtn, autoRefresh, intervalSelect, settingsBtn)
+	top := container.NewHBox(portEntryWrap, addBtn, refreshAllBtn, autoRefresh, intervalSelect, listenersBtn, freePortBtn, orphansBtn, auditBtn, settingsBtn)
 	content := container.NewBorder(top, status, nil, nil, container.NewBorder(rowHeader, nil, nil, nil, list))
 	w.SetContent(content)
 
-	applyAutoRefresh(fyneApp, refresher, svc, intervalSelect.Selected, autoRefresh.Checked, list, status)
+	applyAutoRefresh(fyneApp, refresher, svc, state, alerts, intervalSelect.Selected, autoRefresh.Checked, list, status)
 	w.SetOnClosed(func() {
 		refresher.Stop()
 	})
@@ -234,12 +290,13 @@ func Run() error {
 	} else {
 		status.SetText("Ready.")
 	}
+	alerts.check(state, status)
 	list.Refresh()
 	w.ShowAndRun()
 	return nil
 }
 
-func applyAutoRefresh(app fyne.App, refresher *AutoRefresher, svc *Service, interval string, enabled bool, list *widget.List, status *widget.Label) {
+func applyAutoRefresh(app fyne.App, refresher *AutoRefresher, svc *Service, state *State, alerts *rogueNotifier, interval string, enabled bool, list *widget.List, status *widget.Label) {
 	intervalMs := parseIntervalMs(interval)
 	if enabled {
 		refresher.Start(time.Duration(intervalMs)*time.Millisecond, func() {
@@ -248,6 +305,7 @@ func applyAutoRefresh(app fyne.App, refresher *AutoRefresher, svc *Service, inte
 				if err != nil {
 					status.SetText(fmt.Sprintf("Auto refresh failed: %v", err))
 				}
+				alerts.check(state, status)
 				list.Refresh()
 			})
 		})
@@ -256,6 +314,179 @@ func applyAutoRefresh(app fyne.App, refresher *AutoRefresher, svc *Service, inte
 	}
 }
 
+// rogueNotifier raises a desktop notification the first time each
+// unexpected listener is seen, and for remediation policy events.
+type rogueNotifier struct {
+	app  fyne.App
+	seen map[store.ListenerRecord]bool
+}
+
+func (n *rogueNotifier) check(state *State, status *widget.Label) {
+	n.remediate(state, status)
+	fresh := make([]string, 0)
+	for _, res := range state.RogueListeners() {
+		key := listenerRecord(res)
+		if n.seen[key] {
+			continue
+		}
+		n.seen[key] = true
+		fresh = append(fresh, fmt.Sprintf("%d (%s)", res.Port, firstNonEmpty(res.DisplayName(), res.ExePath, "unknown")))
+	}
+	if len(fresh) == 0 {
+		return
+	}
+	msg := "New listener not in baseline: " + strings.Join(fresh, ", ")
+	status.SetText(msg)
+	n.app.SendNotification(fyne.NewNotification("Port Sentinel", msg))
+}
+
+// remediate surfaces the remediation events queued by the last refresh.
+func (n *rogueNotifier) remediate(state *State, status *widget.Label) {
+	for _, ev := range state.TakeRemediationEvents() {
+		status.SetText(ev.String())
+		n.app.SendNotification(fyne.NewNotification("Port Sentinel", ev.String()))
+	}
+}
+
+func showListenersDialog(w fyne.Window, svc *Service, state *State, status *widget.Label) {
+	cfg := state.SnapshotConfig()
+	info := widget.NewLabel("")
+	rogueBox := container.NewVBox()
+
+	var render func()
+	render = func() {
+		rogueBox.RemoveAll()
+		rogue := state.RogueListeners()
+		if len(rogue) == 0 {
+			rogueBox.Add(widget.NewLabel("No unexpected listeners."))
+		}
+		for _, res := range rogue {
+			r := res
+			text := fmt.Sprintf("%d  %s  %s  user=%s", r.Port, firstNonEmpty(r.DisplayName(), "-"), firstNonEmpty(r.ExePath, "-"), firstNonEmpty(r.User, "-"))
+			allow := widget.NewButton("Allow", func() {
+				if err := svc.AllowListener(r); err != nil {
+					status.SetText(fmt.Sprintf("Allow failed: %v", err))
+					return
+				}
+				render()
+			})
+			rogueBox.Add(container.NewBorder(nil, nil, nil, allow, widget.NewLabel(text)))
+		}
+	}
+	check := func() {
+		rogue, err := svc.CheckBaseline()
+		switch {
+		case errors.Is(err, store.ErrNoBaseline):
+			info.SetText("No baseline recorded yet. Take a snapshot to start.")
+		case err != nil:
+			info.SetText(fmt.Sprintf("Baseline check failed: %v", err))
+		default:
+			info.SetText(fmt.Sprintf("%d unexpected listener(s).", len(rogue)))
+		}
+		render()
+	}
+
+	snapshot := widget.NewButton("Snapshot baseline", func() {
+		baseline, err := svc.SnapshotBaseline()
+		if err != nil {
+			status.SetText(fmt.Sprintf("Baseline snapshot failed: %v", err))
+			return
+		}
+		info.SetText(fmt.Sprintf("Baseline recorded with %d listener(s).", len(baseline.Listeners)))
+		render()
+	})
+	enabled := widget.NewCheck("Alert on listeners not in baseline (checked on every refresh)", func(val bool) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.Baseline.Enabled = val
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Baseline update failed: %v", err))
+			return
+		}
+		if val {
+			if _, err := svc.CheckBaseline(); errors.Is(err, store.ErrNoBaseline) {
+				if _, err := svc.SnapshotBaseline(); err != nil {
+					status.SetText(fmt.Sprintf("Baseline snapshot failed: %v", err))
+				}
+			}
+			check()
+		}
+	})
+	enabled.SetChecked(cfg.Baseline.Enabled)
+
+	check()
+	scroll := container.NewVScroll(rogueBox)
+	scroll.SetMinSize(fyne.NewSize(640, 280))
+	content := container.NewBorder(container.NewVBox(enabled, container.NewHBox(snapshot, widget.NewButton("Check now", check)), info), nil, nil, nil, scroll)
+	dialog.NewCustom("Listener Baseline", "Close", content, w).Show()
+}
+
+// auditDialogLimit caps how many matching entries the audit viewer renders.
+const auditDialogLimit = 500
+
+func showAuditDialog(w fyne.Window, svc *Service) {
+	entries, err := svc.AuditLog()
+	if err != nil && len(entries) == 0 {
+		dialog.ShowError(fmt.Errorf("cannot read audit log: %w", err), w)
+		return
+	}
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Filter by port, PID, process, user or command")
+	outcome := widget.NewSelect([]string{"all", store.AuditOK, store.AuditFailed, store.AuditRefused, store.AuditDryRun}, nil)
+	outcome.SetSelected("all")
+	count := widget.NewLabel("")
+	rows := container.NewVBox()
+
+	render := func() {
+		rows.RemoveAll()
+		query := strings.ToLower(strings.TrimSpace(search.Text))
+		shown := 0
+		for _, e := range entries {
+			if outcome.Selected != "all" && e.Outcome != outcome.Selected {
+				continue
+			}
+			what := firstNonEmpty(string(e.Signal), "-")
+			if e.Action == store.AuditActionNotify || e.Action == store.AuditActionCommand {
+				what = e.Action
+			}
+			line := fmt.Sprintf("%s  %-7s  port %d  PID %d  %s  %s  by %s via %s",
+				e.Time.Local().Format("2006-01-02 15:04:05"), e.Outcome, e.Port, e.PID,
+				firstNonEmpty(e.ProcessName, "-"), what, firstNonEmpty(e.User, "?"), e.Source)
+			if e.Action == store.AuditActionListSockets {
+				line = fmt.Sprintf("%s  %-7s  socket listing  by %s via %s",
+					e.Time.Local().Format("2006-01-02 15:04:05"), e.Outcome, firstNonEmpty(e.User, "?"), e.Source)
+			}
+			if e.Elevated {
+				line += "  [elevated]"
+			}
+			if e.Policy != "" {
+				line += "  [policy " + e.Policy + "]"
+			}
+			detail := firstNonEmpty(e.Error, strings.Join(e.Steps, ", "))
+			if query != "" && !strings.Contains(strings.ToLower(line+" "+e.CommandLine+" "+detail), query) {
+				continue
+			}
+			if shown == auditDialogLimit {
+				break
+			}
+			shown++
+			rows.Add(widget.NewLabelWithStyle(line, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true}))
+			if detail != "" {
+				rows.Add(widget.NewLabel("    " + ellipsis(detail, 120)))
+			}
+		}
+		count.SetText(fmt.Sprintf("%d of %d entries", shown, len(entries)))
+	}
+	search.OnChanged = func(string) { render() }
+	outcome.OnChanged = func(string) { render() }
+	render()
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(820, 400))
+	filters := container.NewBorder(nil, nil, nil, container.NewHBox(outcome, count), search)
+	dialog.NewCustom("Termination Audit Log", "Close", container.NewBorder(filters, nil, nil, nil, scroll), w).Show()
+}
+
 func showSettingsDialog(app fyne.App, w fyne.Window, svc *Service, state *State, list *widget.List, status *widget.Label) {
 	cfg := state.SnapshotConfig()
 	presetBox := container.NewVBox()
@@ -302,6 +533,216 @@ func showSettingsDialog(app fyne.App, w fyne.Window, svc *Service, state *State,
 		}
 	})
 	forceKill.SetChecked(cfg.UI.ForceKillEnabled)
+	gracePeriod := widget.NewEntry()
+	gracePeriod.SetText(strconv.FormatFloat(float64(cfg.UI.KillGracePeriodMs)/1000, 'f', -1, 64))
+	gracePeriod.OnSubmitted = func(val string) {
+		secs, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || secs <= 0 {
+			status.SetText("Grace period must be a positive number of seconds.")
+			return
+		}
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.UI.KillGracePeriodMs = int(secs * 1000)
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Grace period update failed: %v", err))
+			return
+		}
+		status.SetText(fmt.Sprintf("Waiting up to %gs for processes to exit.", secs))
+	}
+	graceRow := container.NewBorder(nil, nil, widget.NewLabel("Grace period before escalating (seconds, press Enter)"), nil, gracePeriod)
+
+	fingerprint := widget.NewCheck("Fingerprint services on listening ports (active local probes)", func(val bool) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.Fingerprint.Enabled = val
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Fingerprint update failed: %v", err))
+		}
+	})
+	fingerprint.SetChecked(cfg.Fingerprint.Enabled)
+
+	tlsInspect := widget.NewCheck("Inspect TLS certificates", func(val bool) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.TLSInspection.Enabled = val
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("TLS inspection update failed: %v", err))
+		}
+	})
+	tlsInspect.SetChecked(cfg.TLSInspection.Enabled)
+	expiryDays := widget.NewEntry()
+	expiryDays.SetText(strconv.Itoa(cfg.TLSInspection.ExpiryWarnDays))
+	expiryDays.OnSubmitted = func(val string) {
+		days, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil || days <= 0 {
+			status.SetText("Expiry warning must be a positive number of days.")
+			return
+		}
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.TLSInspection.ExpiryWarnDays = days
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("TLS expiry update failed: %v", err))
+			return
+		}
+		status.SetText(fmt.Sprintf("Warning when certificates expire within %d days.", days))
+	}
+	expiryRow := container.NewBorder(nil, nil, widget.NewLabel("Warn when a certificate expires within (days, press Enter)"), nil, expiryDays)
+
+	introspect := widget.NewCheck("Introspect dev endpoints (Node inspector, actuator, pprof)", func(val bool) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.Introspection.Enabled = val
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Introspection update failed: %v", err))
+		}
+	})
+	introspect.SetChecked(cfg.Introspection.Enabled)
+
+	integrity := widget.NewCheck("Record executable SHA-256 and warn when it changes", func(val bool) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.Integrity.Enabled = val
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Integrity update failed: %v", err))
+		}
+	})
+	integrity.SetChecked(cfg.Integrity.Enabled)
+
+	resources := widget.NewCheck("Sample CPU and memory of port-holding processes (off by default: one ps/wmic call per process each refresh outside Linux)", func(val bool) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.Resources.Enabled = val
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Resource sampling update failed: %v", err))
+		}
+	})
+	resources.SetChecked(cfg.Resources.Enabled)
+	memoryLimit := widget.NewEntry()
+	memoryLimit.SetText(strconv.FormatFloat(cfg.Resources.MemoryWarnGB, 'f', -1, 64))
+	memoryLimit.OnSubmitted = func(val string) {
+		gb, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || gb < 0 {
+			status.SetText("Memory limit must be a number of GB, or 0 to turn the highlight off.")
+			return
+		}
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.Resources.MemoryWarnGB = gb
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Memory limit update failed: %v", err))
+			return
+		}
+		if gb == 0 {
+			status.SetText("Memory highlight turned off.")
+			return
+		}
+		status.SetText(fmt.Sprintf("Highlighting processes above %g GB RSS.", gb))
+	}
+	memoryRow := container.NewBorder(nil, nil, widget.NewLabel("Highlight when RSS exceeds (GB, 0 = off, press Enter)"), nil, memoryLimit)
+
+	orphans := widget.NewCheck("Flag likely orphaned dev servers", func(val bool) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.Orphans.Enabled = val
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Orphan detection update failed: %v", err))
+		}
+	})
+	orphans.SetChecked(cfg.Orphans.Enabled)
+	orphanIdle := widget.NewEntry()
+	orphanIdle.SetText(strconv.Itoa(cfg.Orphans.IdleHours))
+	orphanIdle.OnSubmitted = func(val string) {
+		hours, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil || hours <= 0 {
+			status.SetText("Idle time must be a positive number of hours.")
+			return
+		}
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.Orphans.IdleHours = hours
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Orphan detection update failed: %v", err))
+			return
+		}
+		status.SetText(fmt.Sprintf("Flagging orphans whose project is untouched for %dh.", hours))
+	}
+	orphanRow := container.NewBorder(nil, nil, orphans, nil, container.NewBorder(nil, nil, widget.NewLabel("Project idle for (hours, press Enter)"), nil, orphanIdle))
+
+	interestingEnv := widget.NewEntry()
+	interestingEnv.SetText(strings.Join(cfg.InterestingEnv, ", "))
+	interestingEnv.OnSubmitted = func(val string) {
+		keys := make([]string, 0)
+		for _, key := range strings.Split(val, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.InterestingEnv = keys
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Environment highlight update failed: %v", err))
+			return
+		}
+		status.SetText(fmt.Sprintf("Highlighting %d environment variable(s).", len(keys)))
+	}
+	interestingEnvRow := container.NewBorder(nil, nil, widget.NewLabel("Highlight env vars (comma separated, press Enter)"), nil, interestingEnv)
+
+	healthBtn := widget.NewButton("Health Probes...", func() {
+		showHealthProbeDialog(w, svc, state, list, status)
+	})
+	killPolicyBtn := widget.NewButton("Kill Policy...", func() {
+		showKillPolicyDialog(w, svc, state, list, status)
+	})
+	remediationBtn := widget.NewButton("Remediation...", func() {
+		showRemediationDialog(w, svc, state, status)
+	})
+
+	elevationMethod := widget.NewSelect(append([]string{ports.ElevateAuto}, ports.ElevationMethods()...), func(val string) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.Elevation.Method = val
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Elevation update failed: %v", err))
+		}
+	})
+	elevationMethod.SetSelected(cfg.Elevation.Method)
+	elevation := widget.NewCheck("Offer to retry permission-denied actions as administrator (the kill policy still applies)", func(val bool) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.Elevation.Enabled = val
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Elevation update failed: %v", err))
+		}
+	})
+	elevation.SetChecked(cfg.Elevation.Enabled)
+	elevatedScanBtn := widget.NewButton("Scan as administrator...", func() {
+		if !state.SnapshotConfig().Elevation.Enabled {
+			status.SetText("Enable elevation first.")
+			return
+		}
+		dialog.ShowConfirm("Scan as Administrator",
+			"List listening sockets with administrator rights so ports held by other users' processes show their owner? The scan is recorded in the audit log.",
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				go func() {
+					_, err := svc.RefreshAllElevated(SourceGUI)
+					fyne.Do(func() {
+						if err != nil {
+							status.SetText(fmt.Sprintf("Elevated scan failed: %v", err))
+						} else {
+							status.SetText("Scanned as administrator.")
+						}
+						list.Refresh()
+					})
+				}()
+			}, w)
+	})
+	elevationRow := container.NewBorder(nil, nil, elevation, elevatedScanBtn, elevationMethod)
 
 	content := container.NewVBox(
 		widget.NewLabelWithStyle("Preset Ports", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
@@ -311,20 +752,562 @@ func showSettingsDialog(app fyne.App, w fyne.Window, svc *Service, state *State,
 		customList,
 		widget.NewSeparator(),
 		forceKill,
+		graceRow,
+		fingerprint,
+		tlsInspect,
+		expiryRow,
+		introspect,
+		integrity,
+		resources,
+		memoryRow,
+		orphanRow,
+		interestingEnvRow,
+		elevationRow,
+		container.NewHBox(healthBtn, killPolicyBtn, remediationBtn),
 	)
 
 	dialog.NewCustom("Ports & Settings", "Close", content, w).Show()
 }
 
+// showOrphanCleanupDialog lists the flagged orphans and terminates them
+// together after confirmation; every kill goes through KillProcess.
+func showOrphanCleanupDialog(w fyne.Window, svc *Service, state *State, status *widget.Label, refreshAll func()) {
+	orphans := svc.Orphans()
+	if len(orphans) == 0 {
+		dialog.ShowInformation("Clean Up Orphans", "No likely orphaned dev servers among the watched ports.", w)
+		return
+	}
+	rows := container.NewVBox()
+	for _, res := range orphans {
+		rows.Add(widget.NewLabel(fmt.Sprintf("Port %d — PID %d (%s)", res.Port, res.PID, firstNonEmpty(res.DisplayName(), res.ProcessName, "unknown"))))
+		rows.Add(widget.NewLabel("    " + describeOrphan(res.Orphan)))
+	}
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(620, 220))
+	ack := widget.NewCheck("I understand these processes will be terminated", nil)
+	content := container.NewBorder(widget.NewLabel("Terminate these likely orphans? Kill policy protections still apply."), ack, nil, nil, scroll)
+	dialog.NewCustomConfirm("Clean Up Orphans", "Terminate", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if !ack.Checked {
+			status.SetText("Please acknowledge risk before terminating the processes.")
+			return
+		}
+		cfg := state.SnapshotConfig()
+		opts := KillOptions{
+			Signal:      ports.SignalTERM,
+			GracePeriod: time.Duration(cfg.UI.KillGracePeriodMs) * time.Millisecond,
+			Escalate:    cfg.UI.KillEscalate,
+			Source:      SourceGUI,
+		}
+		status.SetText(fmt.Sprintf("Terminating %d orphan(s)...", len(orphans)))
+		go func() {
+			cleaned := svc.CleanupOrphans(orphans, opts)
+			failed := make([]string, 0)
+			for _, c := range cleaned {
+				if c.Err != nil {
+					failed = append(failed, fmt.Sprintf("PID %d: %v", c.Target.PID, c.Err))
+				}
+			}
+			fyne.Do(func() {
+				if len(failed) == 0 {
+					status.SetText(fmt.Sprintf("Terminated %d orphan(s).", len(cleaned)))
+				} else {
+					status.SetText(fmt.Sprintf("Terminated %d of %d orphan(s); %s", len(cleaned)-len(failed), len(cleaned), strings.Join(failed, "; ")))
+				}
+				refreshAll()
+			})
+		}()
+	}, w).Show()
+}
+
+// showFreePortDialog searches for free ports near the port typed in the main
+// entry, or 3000.
+func showFreePortDialog(w fyne.Window, svc *Service, near string) {
+	if _, err := strconv.Atoi(near); err != nil {
+		near = "3000"
+	}
+	nearEntry := widget.NewEntry()
+	nearEntry.SetText(near)
+	rangeEntry := widget.NewEntry()
+	rangeEntry.SetPlaceHolder("e.g. 3000-3100 (optional)")
+	countSelect := widget.NewSelect([]string{"1", "3", "5", "10"}, nil)
+	countSelect.SetSelected("3")
+	results := container.NewVBox()
+	info := widget.NewLabel("Skips ports in use, reserved ports and the ephemeral range; each candidate is bind-tested.")
+	info.Wrapping = fyne.TextWrapWord
+
+	var findBtn *widget.Button
+	find := func() {
+		opts := ports.FreePortOptions{}
+		var err error
+		if opts.Near, err = strconv.Atoi(strings.TrimSpace(nearEntry.Text)); err != nil || ValidatePort(opts.Near) != nil {
+			info.SetText("Near must be a port between 1 and 65535.")
+			return
+		}
+		if text := strings.TrimSpace(rangeEntry.Text); text != "" {
+			if opts.Range, err = ports.ParsePortRange(text); err != nil {
+				info.SetText(err.Error())
+				return
+			}
+			if !opts.Range.Contains(opts.Near) {
+				info.SetText(fmt.Sprintf("Port %d is outside %s.", opts.Near, opts.Range))
+				return
+			}
+		}
+		opts.Count, _ = strconv.Atoi(countSelect.Selected)
+		findBtn.Disable()
+		info.SetText("Searching...")
+		go func() {
+			found, err := svc.FindFreePorts(opts)
+			fyne.Do(func() {
+				findBtn.Enable()
+				results.RemoveAll()
+				for _, port := range found {
+					text := strconv.Itoa(port)
+					copyBtn := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+						fyne.CurrentApp().Clipboard().SetContent(text)
+					})
+					results.Add(container.NewBorder(nil, nil, nil, copyBtn, widget.NewLabel(text)))
+				}
+				if err != nil {
+					info.SetText(fmt.Sprintf("Search failed: %v", err))
+				} else {
+					info.SetText(fmt.Sprintf("Found %d free port(s).", len(found)))
+				}
+			})
+		}()
+	}
+	findBtn = widget.NewButton("Find", find)
+	nearEntry.OnSubmitted = func(string) { find() }
+	rangeEntry.OnSubmitted = func(string) { find() }
+
+	form := widget.NewForm(
+		widget.NewFormItem("Near", nearEntry),
+		widget.NewFormItem("Range", rangeEntry),
+		widget.NewFormItem("Count", countSelect),
+	)
+	scroll := container.NewVScroll(results)
+	scroll.SetMinSize(fyne.NewSize(360, 160))
+	content := container.NewBorder(container.NewVBox(form, findBtn, info), nil, nil, nil, scroll)
+	dialog.NewCustom("Find Free Port", "Close", content, w).Show()
+}
+
+func showKillPolicyDialog(w fyne.Window, svc *Service, state *State, list *widget.List, status *widget.Label) {
+	cfg := state.SnapshotConfig()
+	update := func(fn func(p *store.KillPolicyConfig)) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			fn(&c.KillPolicy)
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Kill policy update failed: %v", err))
+			return
+		}
+		list.Refresh()
+	}
+
+	protectRoot := widget.NewCheck("Never terminate processes owned by root / SYSTEM", func(val bool) {
+		update(func(p *store.KillPolicyConfig) { p.ProtectRoot = val })
+	})
+	protectRoot.SetChecked(cfg.KillPolicy.ProtectRoot)
+	protectOthers := widget.NewCheck("Never terminate processes owned by other users", func(val bool) {
+		update(func(p *store.KillPolicyConfig) { p.ProtectOtherUsers = val })
+	})
+	protectOthers.SetChecked(cfg.KillPolicy.ProtectOtherUsers)
+	unknownNote := widget.NewLabel("While either is on, processes whose owner cannot be read are protected too; add an allow rule to exempt them.")
+	unknownNote.Wrapping = fyne.TextWrapWord
+
+	rulesBox := container.NewVBox()
+	var renderRules func()
+	renderRules = func() {
+		rulesBox.RemoveAll()
+		rules := state.SnapshotConfig().KillPolicy.Rules
+		for i, rule := range rules {
+			idx := i
+			text := rule.Action + ": " + describeKillRule(rule, "matched")
+			remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				update(func(p *store.KillPolicyConfig) {
+					if idx < len(p.Rules) {
+						p.Rules = append(p.Rules[:idx], p.Rules[idx+1:]...)
+					}
+				})
+				renderRules()
+			})
+			rulesBox.Add(container.NewBorder(nil, nil, nil, remove, widget.NewLabel(text)))
+		}
+		if len(rules) == 0 {
+			rulesBox.Add(widget.NewLabel("No rules."))
+		}
+	}
+	renderRules()
+
+	portEntry := widget.NewEntry()
+	portEntry.SetPlaceHolder("Port (optional)")
+	fieldSelect := widget.NewSelect([]string{store.MatchProcessName, store.MatchExePath, store.MatchCommandLine}, nil)
+	fieldSelect.SetSelected(store.MatchProcessName)
+	patternEntry := widget.NewEntry()
+	patternEntry.SetPlaceHolder("Pattern, e.g. postgres* (optional)")
+	actionSelect := widget.NewSelect([]string{store.KillProtect, store.KillForce, store.KillAllow}, nil)
+	actionSelect.SetSelected(store.KillProtect)
+	reasonEntry := widget.NewEntry()
+	reasonEntry.SetPlaceHolder("Reason (optional)")
+	addBtn := widget.NewButton("Add rule", func() {
+		rule := store.KillRule{
+			Field:   fieldSelect.Selected,
+			Pattern: strings.TrimSpace(patternEntry.Text),
+			Action:  actionSelect.Selected,
+			Reason:  strings.TrimSpace(reasonEntry.Text),
+		}
+		if text := strings.TrimSpace(portEntry.Text); text != "" {
+			port, err := strconv.Atoi(text)
+			if err != nil || port < 1 || port > 65535 {
+				status.SetText("Invalid port.")
+				return
+			}
+			rule.Port = port
+		}
+		if rule.Port == 0 && rule.Pattern == "" {
+			status.SetText("A kill rule needs a port, a pattern or both.")
+			return
+		}
+		if rule.Pattern != "" {
+			if _, err := compilePattern(rule.Pattern, false); err != nil {
+				status.SetText(fmt.Sprintf("Invalid pattern: %v", err))
+				return
+			}
+		}
+		update(func(p *store.KillPolicyConfig) { p.Rules = append(p.Rules, rule) })
+		portEntry.SetText("")
+		patternEntry.SetText("")
+		reasonEntry.SetText("")
+		renderRules()
+	})
+
+	scroll := container.NewVScroll(rulesBox)
+	scroll.SetMinSize(fyne.NewSize(560, 200))
+	form := container.NewGridWithColumns(5, portEntry, fieldSelect, patternEntry, actionSelect, reasonEntry)
+	content := container.NewBorder(
+		container.NewVBox(widget.NewLabel("PID 1 is always protected."), protectRoot, protectOthers, unknownNote, widget.NewSeparator()),
+		container.NewVBox(widget.NewSeparator(), form, addBtn),
+		nil, nil, scroll,
+	)
+	dialog.NewCustom("Kill Policy", "Close", content, w).Show()
+}
+
+func showRemediationDialog(w fyne.Window, svc *Service, state *State, status *widget.Label) {
+	update := func(fn func(r *store.RemediationConfig)) bool {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			fn(&c.Remediation)
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Remediation update failed: %v", err))
+			return false
+		}
+		return true
+	}
+
+	dryRun := widget.NewCheck("Dry run: only record in the audit log what policies would do", func(val bool) {
+		update(func(r *store.RemediationConfig) { r.DryRun = val })
+	})
+	dryRun.SetChecked(state.SnapshotConfig().Remediation.DryRun)
+
+	policiesBox := container.NewVBox()
+	var renderPolicies func()
+	renderPolicies = func() {
+		policiesBox.RemoveAll()
+		policies := state.SnapshotConfig().Remediation.Policies
+		for i, policy := range policies {
+			idx := i
+			enabled := widget.NewCheck("", func(val bool) {
+				update(func(r *store.RemediationConfig) {
+					if idx < len(r.Policies) {
+						r.Policies[idx].Enabled = val
+					}
+				})
+			})
+			enabled.SetChecked(policy.Enabled)
+			policyDryRun := widget.NewCheck("Dry run", func(val bool) {
+				update(func(r *store.RemediationConfig) {
+					if idx < len(r.Policies) {
+						r.Policies[idx].DryRun = val
+					}
+				})
+			})
+			policyDryRun.SetChecked(policy.DryRun)
+			remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				update(func(r *store.RemediationConfig) {
+					if idx < len(r.Policies) {
+						r.Policies = append(r.Policies[:idx], r.Policies[idx+1:]...)
+					}
+				})
+				renderPolicies()
+			})
+			policiesBox.Add(container.NewBorder(nil, nil, enabled, container.NewHBox(policyDryRun, remove), widget.NewLabel(describeRemediationPolicy(policy))))
+		}
+		if len(policies) == 0 {
+			policiesBox.Add(widget.NewLabel("No policies."))
+		}
+	}
+	renderPolicies()
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Name")
+	portEntry := widget.NewEntry()
+	portEntry.SetPlaceHolder("Port (optional)")
+	fieldSelect := widget.NewSelect([]string{store.MatchProcessName, store.MatchExePath, store.MatchCommandLine}, nil)
+	fieldSelect.SetSelected(store.MatchProcessName)
+	patternEntry := widget.NewEntry()
+	patternEntry.SetPlaceHolder("Pattern, e.g. node (optional)")
+	negate := widget.NewCheck("Anything but the pattern", nil)
+	cwdEntry := widget.NewEntry()
+	cwdEntry.SetPlaceHolder("Working dir under, e.g. ~/src (optional)")
+	parentGone := widget.NewCheck("Parent process gone", nil)
+	heldEntry := widget.NewEntry()
+	heldEntry.SetPlaceHolder("Held for seconds (optional)")
+
+	notify := widget.NewCheck("Notify", nil)
+	notify.SetChecked(true)
+	sendSignal := widget.NewCheck("Send", nil)
+	signalOptions := make([]string, 0, len(ports.Signals()))
+	for _, sig := range ports.Signals() {
+		signalOptions = append(signalOptions, string(sig))
+	}
+	signalSelect := widget.NewSelect(signalOptions, nil)
+	signalSelect.SetSelected(string(ports.SignalTERM))
+	commandEntry := widget.NewEntry()
+	commandEntry.SetPlaceHolder("Run command (optional, use {port}, {pid}, {name})")
+	startDry := widget.NewCheck("Start in dry run", nil)
+	startDry.SetChecked(true)
+
+	addBtn := widget.NewButton("Add policy", func() {
+		policy := store.RemediationPolicy{
+			Name:    strings.TrimSpace(nameEntry.Text),
+			Enabled: true,
+			DryRun:  startDry.Checked,
+			Match: store.RemediationMatch{
+				Field:      fieldSelect.Selected,
+				Pattern:    strings.TrimSpace(patternEntry.Text),
+				Negate:     negate.Checked,
+				CwdUnder:   strings.TrimSpace(cwdEntry.Text),
+				ParentGone: parentGone.Checked,
+			},
+		}
+		if text := strings.TrimSpace(portEntry.Text); text != "" {
+			port, err := strconv.Atoi(text)
+			if err != nil {
+				status.SetText("Invalid port.")
+				return
+			}
+			policy.Match.Port = port
+		}
+		if text := strings.TrimSpace(heldEntry.Text); text != "" {
+			secs, err := strconv.Atoi(text)
+			if err != nil || secs < 0 {
+				status.SetText("Invalid hold duration.")
+				return
+			}
+			policy.Match.MinHeldSec = secs
+		}
+		if notify.Checked {
+			policy.Actions = append(policy.Actions, store.RemediationAction{Type: store.RemediateNotify})
+		}
+		if sendSignal.Checked {
+			policy.Actions = append(policy.Actions, store.RemediationAction{Type: store.RemediateSignal, Signal: ports.Signal(signalSelect.Selected)})
+		}
+		if command := strings.Fields(commandEntry.Text); len(command) > 0 {
+			policy.Actions = append(policy.Actions, store.RemediationAction{Type: store.RemediateCommand, Command: command})
+		}
+		if err := ValidateRemediationPolicy(policy); err != nil {
+			status.SetText(fmt.Sprintf("Invalid policy: %v", err))
+			return
+		}
+		if !update(func(r *store.RemediationConfig) { r.Policies = append(r.Policies, policy) }) {
+			return
+		}
+		for _, entry := range []*widget.Entry{nameEntry, portEntry, patternEntry, cwdEntry, heldEntry, commandEntry} {
+			entry.SetText("")
+		}
+		renderPolicies()
+	})
+
+	scroll := container.NewVScroll(policiesBox)
+	scroll.SetMinSize(fyne.NewSize(640, 200))
+	form := container.NewVBox(
+		container.NewGridWithColumns(4, nameEntry, portEntry, fieldSelect, patternEntry),
+		container.NewGridWithColumns(4, negate, cwdEntry, parentGone, heldEntry),
+		container.NewBorder(nil, nil, container.NewHBox(notify, sendSignal, signalSelect), startDry, commandEntry),
+		addBtn,
+	)
+	content := container.NewBorder(
+		container.NewVBox(widget.NewLabel("Policies are evaluated after each refresh; each match acts once per process."), dryRun, widget.NewSeparator()),
+		container.NewVBox(widget.NewSeparator(), form),
+		nil, nil, scroll,
+	)
+	dialog.NewCustom("Remediation Policies", "Close", content, w).Show()
+}
+
+func showHealthProbeDialog(w fyne.Window, svc *Service, state *State, list *widget.List, status *widget.Label) {
+	portOptions := make([]string, 0)
+	for _, port := range state.GetPorts() {
+		portOptions = append(portOptions, strconv.Itoa(port))
+	}
+	typeSelect := widget.NewSelect([]string{ports.ProbeTCP, ports.ProbeHTTP, ports.ProbeHTTPS, ports.ProbeCommand}, nil)
+	target := widget.NewEntry()
+	target.SetPlaceHolder("/healthz, full URL, or command (use {port})")
+	expectStatus := widget.NewEntry()
+	expectStatus.SetPlaceHolder("Expected status (blank = any 2xx/3xx)")
+	expectBody := widget.NewEntry()
+	expectBody.SetPlaceHolder("Expected body substring")
+	timeout := widget.NewEntry()
+	timeout.SetPlaceHolder("Timeout ms (default 2000)")
+
+	configured := widget.NewLabel("")
+	refreshConfigured := func() {
+		cfg := state.SnapshotConfig()
+		probed := make([]int, 0, len(cfg.HealthProbes))
+		for port := range cfg.HealthProbes {
+			probed = append(probed, port)
+		}
+		sort.Ints(probed)
+		lines := make([]string, 0, len(probed))
+		for _, port := range probed {
+			lines = append(lines, fmt.Sprintf("%d: %s", port, cfg.HealthProbes[port].Type))
+		}
+		configured.SetText(firstNonEmpty(strings.Join(lines, "\n"), "No probes configured."))
+	}
+	refreshConfigured()
+
+	portSelect := widget.NewSelect(portOptions, func(value string) {
+		port, _ := strconv.Atoi(value)
+		probe, ok := state.SnapshotConfig().HealthProbes[port]
+		if !ok {
+			probe = ports.HealthProbe{Type: ports.ProbeTCP}
+		}
+		typeSelect.SetSelected(probe.Type)
+		if probe.Type == ports.ProbeCommand {
+			target.SetText(strings.Join(probe.Command, " "))
+		} else {
+			target.SetText(probe.Path)
+		}
+		expectStatus.SetText("")
+		if probe.ExpectStatus != 0 {
+			expectStatus.SetText(strconv.Itoa(probe.ExpectStatus))
+		}
+		expectBody.SetText(probe.ExpectBody)
+		timeout.SetText("")
+		if probe.TimeoutMs != 0 {
+			timeout.SetText(strconv.Itoa(probe.TimeoutMs))
+		}
+	})
+
+	save := widget.NewButton("Save", func() {
+		port, err := strconv.Atoi(portSelect.Selected)
+		if err != nil {
+			status.SetText("Select a port first.")
+			return
+		}
+		probe := ports.HealthProbe{Type: typeSelect.Selected, ExpectBody: expectBody.Text}
+		if probe.Type == ports.ProbeCommand {
+			probe.Command = strings.Fields(target.Text)
+		} else {
+			probe.Path = strings.TrimSpace(target.Text)
+		}
+		if v := strings.TrimSpace(expectStatus.Text); v != "" {
+			if probe.ExpectStatus, err = strconv.Atoi(v); err != nil {
+				status.SetText("Invalid expected status.")
+				return
+			}
+		}
+		if v := strings.TrimSpace(timeout.Text); v != "" {
+			if probe.TimeoutMs, err = strconv.Atoi(v); err != nil {
+				status.SetText("Invalid timeout.")
+				return
+			}
+		}
+		if err := svc.SetHealthProbeAndSave(port, &probe); err != nil {
+			status.SetText(fmt.Sprintf("Health probe update failed: %v", err))
+			return
+		}
+		refreshConfigured()
+		list.Refresh()
+		status.SetText(fmt.Sprintf("Health probe for port %d saved.", port))
+	})
+	remove := widget.NewButton("Remove", func() {
+		port, err := strconv.Atoi(portSelect.Selected)
+		if err != nil {
+			return
+		}
+		if err := svc.SetHealthProbeAndSave(port, nil); err != nil {
+			status.SetText(fmt.Sprintf("Health probe update failed: %v", err))
+			return
+		}
+		refreshConfigured()
+		list.Refresh()
+		status.SetText(fmt.Sprintf("Health probe for port %d removed.", port))
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Port", portSelect),
+		widget.NewFormItem("Type", typeSelect),
+		widget.NewFormItem("Target", target),
+		widget.NewFormItem("Status", expectStatus),
+		widget.NewFormItem("Body", expectBody),
+		widget.NewFormItem("Timeout", timeout),
+	)
+	content := container.NewVBox(form, container.NewHBox(save, remove), widget.NewSeparator(), configured)
+	dialog.NewCustom("Health Probes", "Close", content, w).Show()
+}
+
 func showKillDialog(app fyne.App, w fyne.Window, svc *Service, state *State, result ports.PortScanResult, status *widget.Label, list *widget.List) {
-	force := widget.NewCheck("Force terminate", nil)
 	cfg := state.SnapshotConfig()
-	force.SetChecked(cfg.UI.ForceKillEnabled)
+	signalOptions := make([]string, 0, len(ports.Signals()))
+	signalByOption := make(map[string]ports.Signal)
+	for _, sig := range ports.Signals() {
+		option := fmt.Sprintf("%s — %s", sig.Label(), sig.Description())
+		signalOptions = append(signalOptions, option)
+		signalByOption[option] = sig
+	}
+	// Select cannot disable single options, so unsupported signals are
+	// labelled as such, explained when picked and refused on Send.
+	unsupported := widget.NewLabel("")
+	unsupported.Hide()
+	signalSelect := widget.NewSelect(signalOptions, func(option string) {
+		if sig := signalByOption[option]; !sig.Supported() {
+			unsupported.SetText(fmt.Sprintf("ℹ SIG%s cannot be delivered on this platform; pick another signal", sig))
+			unsupported.Show()
+			return
+		}
+		unsupported.Hide()
+	})
+	preferred := svc.PreferredSignal(result)
+	if !preferred.Supported() {
+		preferred = ports.SignalTERM
+	}
+	for option, sig := range signalByOption {
+		if sig == preferred {
+			signalSelect.SetSelected(option)
+		}
+	}
+	grace := time.Duration(cfg.UI.KillGracePeriodMs) * time.Millisecond
+	escalate := widget.NewCheck(fmt.Sprintf("Force terminate if still running after %gs", grace.Seconds()), func(val bool) {
+		if err := svc.UpdateUIConfig(func(c *store.Config) error {
+			c.UI.KillEscalate = val
+			return nil
+		}); err != nil {
+			status.SetText(fmt.Sprintf("Escalation update failed: %v", err))
+		}
+	})
+	escalate.SetChecked(cfg.UI.KillEscalate)
 	ack := widget.NewCheck("I understand this may terminate critical system/app processes", nil)
 
-	message := fmt.Sprintf("Terminate PID %d (%s) on port %d?", result.PID, result.ProcessName, result.Port)
+	name := result.ProcessName
+	if label := result.DisplayName(); label != name {
+		name = fmt.Sprintf("%s — %s", name, label)
+	}
+	message := fmt.Sprintf("Terminate PID %d (%s) on port %d?", result.PID, name, result.Port)
 	exePath := firstNonEmpty(strings.TrimSpace(result.ExePath), "-")
-	cmdPreview := ellipsis(maskSensitiveArgs(strings.TrimSpace(result.CommandLine)), 96)
+	cmdPreview := ellipsis(util.MaskSensitiveArgs(strings.TrimSpace(result.CommandLine)), 96)
 	if cmdPreview == "" {
 		cmdPreview = "-"
 	}
@@ -332,10 +1315,42 @@ func showKillDialog(app fyne.App, w fyne.Window, svc *Service, state *State, res
 		widget.NewLabel(message),
 		widget.NewLabel("Executable: "+exePath),
 		widget.NewLabel("Command: "+cmdPreview),
-		force,
-		ack,
 	)
-	dialog.NewCustomConfirm("Terminate Process", "Terminate", "Cancel", content, func(ok bool) {
+	if fwd := forwardSummary(result); fwd != "" {
+		content.Add(widget.NewLabel("Forwarding: " + fwd))
+	}
+	if result.Service != nil {
+		content.Add(widget.NewLabel("Service: " + serviceSummary(result.Service)))
+	}
+	for _, warning := range result.Warnings {
+		content.Add(widget.NewLabel("⚠ " + warning))
+	}
+	if decision := svc.KillPolicy(result); decision.Force {
+		content.Add(widget.NewLabel("ℹ " + decision.Reason + "; SIGKILL will be sent"))
+	}
+	impact := container.NewVBox(widget.NewLabel("Checking impact..."))
+	content.Add(impact)
+	go func() {
+		preview := svc.KillImpact(result)
+		fyne.Do(func() {
+			impact.RemoveAll()
+			lines := preview.Lines()
+			if len(lines) == 0 {
+				lines = []string{"No child processes, other ports or live connections found"}
+			}
+			for _, line := range lines {
+				impact.Add(widget.NewLabel(line))
+			}
+			for _, line := range preview.Errors {
+				impact.Add(widget.NewLabel("Impact check incomplete: " + line))
+			}
+		})
+	}()
+	content.Add(container.NewBorder(nil, nil, widget.NewLabel("Signal"), nil, signalSelect))
+	content.Add(unsupported)
+	content.Add(escalate)
+	content.Add(ack)
+	dialog.NewCustomConfirm("Signal Process", "Send", "Cancel", content, func(ok bool) {
 		if !ok {
 			return
 		}
@@ -343,13 +1358,130 @@ func showKillDialog(app fyne.App, w fyne.Window, svc *Service, state *State, res
 			status.SetText("Please acknowledge risk before terminating the process.")
 			return
 		}
+		sig := signalByOption[signalSelect.Selected]
+		if !sig.Supported() {
+			status.SetText(fmt.Sprintf("SIG%s is not supported on this platform.", sig))
+			return
+		}
+		if err := svc.RememberSignal(result, sig); err != nil {
+			status.SetText(fmt.Sprintf("Saving signal preference failed: %v", err))
+		}
+		opts := KillOptions{Signal: sig, GracePeriod: grace, Escalate: escalate.Checked, Source: SourceGUI}
+		runKill(w, svc, state, result, opts, status, list)
+	}, w).Show()
+}
+
+// runKill signals result in the background and, when the OS refuses with a
+// permission error and elevation is enabled, offers an elevated retry that
+// the user has to confirm on its own.
+func runKill(w fyne.Window, svc *Service, state *State, result ports.PortScanResult, opts KillOptions, status *widget.Label, list *widget.List) {
+	status.SetText(fmt.Sprintf("Sending %s to PID %d...", opts.Signal.Label(), result.PID))
+	go func() {
+		report, err := svc.KillProcess(result, opts)
+		fyne.Do(func() {
+			switch {
+			case err != nil && len(report.Steps) > 0:
+				status.SetText(fmt.Sprintf("Terminate PID %d: %s — %v", result.PID, report.Summary(), err))
+			case err != nil:
+				status.SetText(fmt.Sprintf("Terminate failed: %v", err))
+			default:
+				status.SetText(fmt.Sprintf("PID %d: %s", result.PID, report.Summary()))
+			}
+			_, _ = svc.RefreshAll()
+			list.Refresh()
+
+			if !errors.Is(err, ports.ErrPermission) || opts.Elevated {
+				return
+			}
+			cfg := state.SnapshotConfig()
+			if !cfg.Elevation.Enabled {
+				status.SetText(status.Text + " (enable elevation under Ports & Settings to retry as administrator)")
+				return
+			}
+			msg := fmt.Sprintf("The OS refused to signal PID %d (%s).\n\nRetry with administrator rights via %s? Only this single signal is elevated and the attempt is recorded in the audit log. Elevation does not override the kill policy; this process already passed it.",
+				result.PID, result.DisplayName(), cfg.Elevation.Method)
+			dialog.ShowConfirm("Retry as Administrator", msg, func(ok bool) {
+				if !ok {
+					return
+				}
+				elevated := opts
+				elevated.Elevated = true
+				runKill(w, svc, state, result, elevated, status, list)
+			}, w)
+		})
+	}()
+}
+
+func showRestartDialog(w fyne.Window, svc *Service, state *State, result ports.PortScanResult, status *widget.Label, list *widget.List) {
+	cfg := state.SnapshotConfig()
+	inheritEnv := widget.NewCheck("Relaunch with Port Sentinel's environment instead", nil)
+	launch := container.NewVBox(widget.NewLabel("Reading how the process was started..."))
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Restart PID %d (%s) on port %d?", result.PID, result.DisplayName(), result.Port)),
+		launch,
+		widget.NewLabel("The process is terminated, then started again with the same arguments, working directory and environment."),
+	)
+	var (
+		spec     ports.LaunchSpec
+		specErr  error
+		captured bool
+	)
+	go func() {
+		got, err := svc.PreviewRestart(result)
+		fyne.Do(func() {
+			spec, specErr, captured = got, err, true
+			launch.RemoveAll()
+			if err != nil {
+				launch.Add(widget.NewLabel(fmt.Sprintf("Cannot restart: %v", err)))
+				return
+			}
+			launch.Add(widget.NewLabel("Relaunch: " + ellipsis(util.MaskSensitiveArgs(strings.Join(got.Argv, " ")), 96)))
+			if runtime.GOOS != "linux" {
+				launch.Add(widget.NewLabel("ℹ Arguments are split from the reported command line; check the quoting before restarting."))
+			}
+			launch.Add(widget.NewLabel("Directory: " + firstNonEmpty(got.Dir, "-")))
+			if got.Env == nil {
+				launch.Add(widget.NewLabel("⚠ The environment of this process is not readable; its variables (PORT, NODE_ENV, ...) would be lost."))
+				launch.Add(inheritEnv)
+			}
+		})
+	}()
+	dialog.NewCustomConfirm("Restart Process", "Restart", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		switch {
+		case !captured:
+			status.SetText("Still reading how the process was started; try again.")
+			return
+		case specErr != nil:
+			status.SetText(fmt.Sprintf("Restart failed: %v", specErr))
+			return
+		case spec.Env == nil && !inheritEnv.Checked:
+			status.SetText("Restart cancelled: the environment is not readable and relaunching with Port Sentinel's was not allowed.")
+			return
+		}
+		opts := RestartOptions{
+			Kill: KillOptions{
+				Signal:      ports.SignalTERM,
+				GracePeriod: time.Duration(cfg.UI.KillGracePeriodMs) * time.Millisecond,
+				Escalate:    cfg.UI.KillEscalate,
+				Source:      SourceGUI,
+			},
+			InheritEnv: inheritEnv.Checked,
+		}
+		status.SetText(fmt.Sprintf("Restarting PID %d...", result.PID))
 		go func() {
-			err := svc.KillProcess(result.PID, force.Checked)
+			report, err := svc.RestartProcess(result, opts)
 			fyne.Do(func() {
-				if err != nil {
-					status.SetText(fmt.Sprintf("Terminate failed: %v", err))
-				} else {
-					status.SetText(fmt.Sprintf("Terminated PID %d.", result.PID))
+				summary := report.Summary()
+				switch {
+				case err != nil && summary != "":
+					status.SetText(fmt.Sprintf("Restart port %d: %s — %v", result.Port, summary, err))
+				case err != nil:
+					status.SetText(fmt.Sprintf("Restart failed: %v", err))
+				default:
+					status.SetText(fmt.Sprintf("Restart port %d: %s", result.Port, summary))
 				}
 				_, _ = svc.RefreshAll()
 				list.Refresh()
@@ -358,6 +1490,154 @@ func showKillDialog(app fyne.App, w fyne.Window, svc *Service, state *State, res
 	}, w).Show()
 }
 
+func showDetailsDialog(w fyne.Window, svc *Service, result ports.PortScanResult) {
+	rows := container.NewVBox()
+	addRow := func(name, value string) {
+		if strings.TrimSpace(value) == "" {
+			return
+		}
+		rows.Add(container.NewHBox(
+			widget.NewLabelWithStyle(name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			widget.NewLabel(value),
+		))
+	}
+	addRow("Status", string(result.Status))
+	if result.PID > 0 {
+		addRow("PID", strconv.Itoa(result.PID))
+	}
+	addRow("Process", result.ProcessName)
+	if !result.StartTime.IsZero() {
+		addRow("Started", result.StartTime.Local().Format("2006-01-02 15:04:05"))
+	}
+	if class := result.Classification; class != nil {
+		addRow("Label", strings.TrimSpace(class.Icon+" "+class.Label))
+		addRow("Category", class.Category)
+	}
+	exe := result.ExePath
+	if result.ExeDeleted {
+		exe += " (deleted)"
+	}
+	addRow("Executable", exe)
+	addRow("SHA-256", result.ExeSHA256)
+	addRow("Command", ellipsis(util.MaskSensitiveArgs(result.CommandLine), 96))
+	addRow("Address", result.LocalAddress)
+	addRow("Forwarding", forwardSummary(result))
+	if result.Service != nil {
+		addRow("Service", serviceSummary(result.Service))
+	}
+	if h := result.Health; h != nil {
+		addRow("Health", fmt.Sprintf("%s in %dms — %s", h.Status, h.LatencyMs, h.Detail))
+	}
+	if cert := result.TLS; cert != nil {
+		addRow("TLS subject", cert.Subject)
+		addRow("TLS issuer", cert.Issuer)
+		addRow("TLS SANs", strings.Join(cert.SANs, ", "))
+		addRow("TLS expires", cert.NotAfter.Local().Format("2006-01-02 15:04"))
+		verified := "yes"
+		if !cert.Verified {
+			verified = "no — " + cert.VerifyError
+		}
+		addRow("TLS verified", verified)
+	}
+	if r := result.Resources; r != nil {
+		addRow("CPU", fmt.Sprintf("%.1f%%  %s", r.CPUPercent, sparkline(r.CPUHistory)))
+		rss := make([]float64, 0, len(r.RSSHistory))
+		for _, v := range r.RSSHistory {
+			rss = append(rss, float64(v))
+		}
+		addRow("Memory (RSS)", fmt.Sprintf("%s  %s", formatBytes(r.RSSBytes), sparkline(rss)))
+	}
+	if result.Orphan != nil {
+		addRow("Likely orphan", describeOrphan(result.Orphan))
+	}
+	addRow("Error", result.Error)
+	for _, warning := range result.Warnings {
+		rows.Add(widget.NewLabel("⚠ " + warning))
+	}
+	if len(result.Endpoints) > 0 {
+		rows.Add(widget.NewSeparator())
+		rows.Add(widget.NewLabelWithStyle("Endpoints", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	}
+	for _, ep := range result.Endpoints {
+		url := ep.URL
+		title := strings.TrimSpace(fmt.Sprintf("[%s] %s", firstNonEmpty(ep.Type, ep.Kind), ep.Title))
+		copyBtn := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+			fyne.CurrentApp().Clipboard().SetContent(url)
+		})
+		rows.Add(container.NewBorder(nil, nil, nil, copyBtn, widget.NewLabel(title)))
+		if ep.Detail != "" && ep.Detail != ep.Title {
+			rows.Add(widget.NewLabel("    " + ep.Detail))
+		}
+		rows.Add(widget.NewLabel("    " + url))
+	}
+	if result.PID > 0 {
+		rows.Add(widget.NewButton("Environment...", func() {
+			showEnvironmentDialog(w, svc, result.PID)
+		}))
+	}
+	if result.ExeSHA256 != "" {
+		rows.Add(widget.NewButton("Trust current binary for this port", func() {
+			if err := svc.TrustExecutable(result.Port); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			dialog.ShowInformation("Binary trusted", fmt.Sprintf("Port %d now expects sha256 %.12s…", result.Port, result.ExeSHA256), w)
+		}))
+	}
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(560, 320))
+	dialog.NewCustom(fmt.Sprintf("Port %d", result.Port), "Close", scroll, w).Show()
+}
+
+func showEnvironmentDialog(w fyne.Window, svc *Service, pid int) {
+	env, err := svc.Environment(pid)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("cannot read environment of PID %d: %w", pid, err), w)
+		return
+	}
+	rows := container.NewVBox()
+	for _, e := range env {
+		label := widget.NewLabelWithStyle(e.Key+"="+ellipsis(e.Value, 120), fyne.TextAlignLeading, fyne.TextStyle{Bold: e.Interesting})
+		if e.Interesting {
+			label.Importance = widget.HighImportance
+		}
+		rows.Add(label)
+	}
+	if len(env) == 0 {
+		rows.Add(widget.NewLabel("Environment is empty."))
+	}
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(640, 360))
+	dialog.NewCustom(fmt.Sprintf("Environment of PID %d", pid), "Close", scroll, w).Show()
+}
+
+func statusText(result ports.PortScanResult) string {
+	text := string(result.Status)
+	if result.Service != nil && result.Service.Protocol != ports.ServiceUnknown {
+		text += " · " + result.Service.Protocol
+	}
+	if h := result.Health; h != nil {
+		text += fmt.Sprintf(" · %s %dms", h.Status, h.LatencyMs)
+	}
+	if r := result.Resources; r != nil {
+		text += fmt.Sprintf(" · %.0f%% %s %s", r.CPUPercent, formatBytes(r.RSSBytes), sparkline(r.CPUHistory))
+	}
+	if result.Orphan != nil {
+		text += " · likely orphan"
+	}
+	if len(result.Warnings) > 0 {
+		text += " ⚠"
+	}
+	return text
+}
+
+func serviceSummary(fp *ports.ServiceFingerprint) string {
+	if fp.Banner == "" {
+		return fp.Protocol
+	}
+	return fmt.Sprintf("%s (%s)", fp.Protocol, fp.Banner)
+}
+
 func getResult(state *State, port int) ports.PortScanResult {
 	state.mu.RLock()
 	defer state.mu.RUnlock()
@@ -372,13 +1652,23 @@ func getResult(state *State, port int) ports.PortScanResult {
 	}
 }
 
-func firstNonEmpty(values ...string) string {
-	for _, val := range values {
-		if strings.TrimSpace(val) != "" {
-			return val
-		}
+func forwardSummary(result ports.PortScanResult) string {
+	if result.Forward == nil {
+		return ""
 	}
-	return ""
+	return result.Forward.Summary()
+}
+
+func parseHexColor(val string) (color.NRGBA, bool) {
+	val = strings.TrimPrefix(strings.TrimSpace(val), "#")
+	if len(val) != 6 {
+		return color.NRGBA{}, false
+	}
+	rgb, err := strconv.ParseUint(val, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 255}, true
 }
 
 func ellipsis(val string, max int) string {
@@ -402,15 +1692,3 @@ func parseIntervalMs(s string) int {
 		return 5000
 	}
 }
-
-var sensitiveArgPattern = regexp.MustCompile(`(?i)\b(password|passwd|pwd|token|secret|api[_-]?key)\s*[:=]\s*([^\s]+)`)
-var bearerTokenPattern = regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`)
-
-func maskSensitiveArgs(input string) string {
-	if strings.TrimSpace(input) == "" {
-		return input
-	}
-	masked := sensitiveArgPattern.ReplaceAllString(input, "$1=***")
-	masked = bearerTokenPattern.ReplaceAllString(masked, "bearer ***")
-	return masked
-}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"port_sentinel/internal/app"
+	"port_sentinel/internal/ports"
+)
+
+// expectation is one PORT=STATUS[:NAME] argument of check.
+type expectation struct {
+	port   int
+	status ports.PortStatus
+	// name, when set, must equal the process name or its classification
+	// label, ignoring case.
+	name string
+}
+
+func (x expectation) String() string {
+	s := fmt.Sprintf("%d=%s", x.port, statusWord(x.status))
+	if x.name != "" {
+		s += ":" + x.name
+	}
+	return s
+}
+
+func parseExpectation(arg string) (expectation, error) {
+	portText, want, ok := strings.Cut(arg, "=")
+	if !ok {
+		return expectation{}, usageErrorf("invalid expectation %q (want PORT=free or PORT=in-use[:NAME])", arg)
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(portText))
+	if err != nil || app.ValidatePort(port) != nil {
+		return expectation{}, usageErrorf("invalid port in %q", arg)
+	}
+	statusText, name, _ := strings.Cut(want, ":")
+	x := expectation{port: port, name: strings.TrimSpace(name)}
+	switch strings.ToLower(strings.TrimSpace(statusText)) {
+	case "free":
+		x.status = ports.StatusFree
+		if x.name != "" {
+			return expectation{}, usageErrorf("a free port has no process: %q", arg)
+		}
+	case "in-use", "in_use", "inuse", "used":
+		x.status = ports.StatusInUse
+	default:
+		return expectation{}, usageErrorf("unknown status %q in %q (want free or in-use)", statusText, arg)
+	}
+	return x, nil
+}
+
+// mismatch explains how res fails x, or returns "" when it holds.
+func (x expectation) mismatch(res ports.PortScanResult) string {
+	if res.Status != x.status {
+		got := statusWord(res.Status)
+		if res.Status == ports.StatusInUse {
+			got += fmt.Sprintf(" by %s (PID %d)", firstNonEmpty(res.DisplayName(), res.ProcessName, "unknown"), res.PID)
+		}
+		return fmt.Sprintf("expected %s, got %s", statusWord(x.status), got)
+	}
+	if x.name != "" && !processNamed(res, x.name) {
+		return fmt.Sprintf("expected %s, got %s (PID %d)", x.name, firstNonEmpty(res.DisplayName(), res.ProcessName, "an unknown process"), res.PID)
+	}
+	return ""
+}
+
+func processNamed(res ports.PortScanResult, name string) bool {
+	base := strings.TrimSuffix(filepath.Base(strings.ReplaceAll(res.ProcessName, "\\", "/")), ".exe")
+	for _, candidate := range []string{base, res.ProcessName, res.DisplayName()} {
+		if candidate != "" && strings.EqualFold(candidate, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func statusWord(status ports.PortStatus) string {
+	switch status {
+	case ports.StatusFree:
+		return "free"
+	case ports.StatusInUse:
+		return "in-use"
+	}
+	return strings.ToLower(string(status))
+}
+
+// cmdCheck scans every expected port once. Unmet expectations exit 1; a port
+// that could not be scanned exits 2, since nothing can be said about it.
+func cmdCheck(args []string, e *env) (int, error) {
+	fs := newFlagSet("check", e)
+	quiet := fs.Bool("quiet", false, "print only mismatches")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage, err
+	}
+	if len(rest) == 0 {
+		return ExitUsage, usageErrorf("give one or more PORT=STATUS[:NAME] expectations")
+	}
+	expectations := make([]expectation, 0, len(rest))
+	list := make([]int, 0, len(rest))
+	seen := map[int]bool{}
+	for _, arg := range rest {
+		x, err := parseExpectation(arg)
+		if err != nil {
+			return ExitUsage, err
+		}
+		expectations = append(expectations, x)
+		if !seen[x.port] {
+			seen[x.port] = true
+			list = append(list, x.port)
+		}
+	}
+
+	results, scanErr := e.svc.ScanPorts(list)
+	byPort := make(map[int]ports.PortScanResult, len(results))
+	for _, res := range results {
+		byPort[res.Port] = res
+	}
+
+	failed, unscanned := 0, 0
+	for _, x := range expectations {
+		res, ok := byPort[x.port]
+		if !ok || res.Status == ports.StatusUnknown {
+			unscanned++
+			fmt.Fprintf(e.stdout, "ERROR %s: could not scan port %d%s\n", x, x.port, errorSuffix(res.Error))
+			continue
+		}
+		if why := x.mismatch(res); why != "" {
+			failed++
+			fmt.Fprintf(e.stdout, "FAIL  %s: %s\n", x, why)
+		} else if !*quiet {
+			fmt.Fprintf(e.stdout, "ok    %s\n", x)
+		}
+	}
+
+	switch {
+	case scanErr != nil:
+		return ExitScanError, scanErr
+	case unscanned > 0:
+		return ExitScanError, fmt.Errorf("%d port(s) could not be scanned", unscanned)
+	case failed > 0:
+		return ExitFailure, fmt.Errorf("%d of %d expectation(s) failed", failed, len(expectations))
+	}
+	return ExitOK, nil
+}
+
+func errorSuffix(msg string) string {
+	if msg == "" {
+		return ""
+	}
+	return ": " + msg
+}
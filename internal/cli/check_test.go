@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"port_sentinel/internal/ports"
+)
+
+func TestParseExpectation(t *testing.T) {
+	x, err := parseExpectation("5432=in-use:postgres")
+	if err != nil || x.port != 5432 || x.status != ports.StatusInUse || x.name != "postgres" {
+		t.Fatalf("unexpected expectation %+v, %v", x, err)
+	}
+	if x, err := parseExpectation("3000=FREE"); err != nil || x.status != ports.StatusFree {
+		t.Fatalf("unexpected expectation %+v, %v", x, err)
+	}
+	for _, bad := range []string{"3000", "abc=free", "3000=busy", "3000=free:node", "70000=free"} {
+		if _, err := parseExpectation(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestCheckExitCodes(t *testing.T) {
+	scanner := &fakeScanner{results: []ports.PortScanResult{
+		{Port: 5432, Status: ports.StatusInUse, PID: 77, ProcessName: "/usr/lib/postgresql/bin/postgres"},
+		{Port: 6379, Status: ports.StatusInUse, PID: 78, ProcessName: "redis-server", Classification: &ports.Classification{Label: "Redis"}},
+	}}
+	e, stdout, _ := newTestEnv(scanner, &fakeRepo{})
+
+	if code := run([]string{"check", "3000=free", "5432=in-use:postgres", "6379=in-use:redis"}, e); code != ExitOK {
+		t.Fatalf("expected all expectations to hold, got %d:\n%s", code, stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"check", "--quiet", "3000=free", "5432=free", "6379=in-use:mysql"}, e); code != ExitFailure {
+		t.Fatalf("expected a failed expectation to exit 1, got %d", code)
+	}
+	out := stdout.String()
+	if strings.Contains(out, "3000") || !strings.Contains(out, "FAIL  5432=free: expected free, got in-use") || !strings.Contains(out, "expected mysql, got Redis") {
+		t.Fatalf("unexpected check output:\n%s", out)
+	}
+
+	scanner.scanErr = errors.New("lsof missing")
+	if code := run([]string{"check", "3000=free"}, e); code != ExitScanError {
+		t.Fatalf("expected a scan error to exit 2, got %d", code)
+	}
+	if code := run([]string{"check", "3000"}, e); code != ExitUsage {
+		t.Fatalf("expected a malformed expectation to exit 64, got %d", code)
+	}
+}
@@ -0,0 +1,510 @@
+// Package cli implements the headless subcommands. It depends only on the
+// app service layer, so it works in CGO-free builds, over SSH and in scripts.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"port_sentinel/internal/app"
+	"port_sentinel/internal/output"
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+// Exit codes.
+const (
+	ExitOK        = 0
+	ExitFailure   = 1
+	ExitScanError = 2
+	ExitUsage     = 64
+)
+
+const usage = `Usage: portsentinel <command> [flags]
+
+Without a command the desktop UI starts (CGO builds only).
+
+Commands:
+  list                  scan the watched ports and print their status
+  scan [--all] PORT...  scan the given ports, or every listening socket
+  check PORT=STATUS...  compare ports with expectations, e.g. 3000=free
+                        5432=in-use:postgres (STATUS is free or in-use)
+  wait [flags] PORT...  block until the ports are --until in-use (default),
+                        free or healthy; --timeout 60s
+  free-port [flags]     print free ports, e.g. --near 3000 --range 3000-3100
+                        --count 3
+  kill [flags] PORT     terminate the process holding PORT (needs --yes)
+  pin PORT              keep PORT at the top of the list
+  unpin PORT            stop pinning PORT
+  add PORT...           watch custom ports
+  remove PORT...        stop watching custom ports
+  help                  show this help
+
+list and scan accept --format table|json|ndjson|csv, or --template with a
+Go text/template such as '{{.Port}} {{.Status}} {{.ProcessName}}'.
+
+Exit codes: 0 ok, 1 failure (for check: an expectation failed; for wait: timed
+out), 2 scan error, 64 usage error.
+`
+
+// usageError reports bad arguments; it is printed with a pointer to help.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// env is what a command runs against. configErr is why the saved config
+// could not be loaded; the defaults are in state instead.
+type env struct {
+	state     *app.State
+	svc       *app.Service
+	stdout    io.Writer
+	stderr    io.Writer
+	configErr error
+}
+
+// Run executes one subcommand and returns the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || isHelp(args[0]) {
+		return help(args, stdout, stderr)
+	}
+	cfg, err := store.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: %v; using default settings\n", err)
+	}
+	state := app.NewState(cfg)
+	return run(args, &env{state: state, svc: app.NewDefaultService(state), stdout: stdout, stderr: stderr, configErr: err})
+}
+
+func help(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return ExitUsage
+	}
+	fmt.Fprint(stdout, usage)
+	return ExitOK
+}
+
+func isHelp(arg string) bool {
+	switch arg {
+	case "help", "-h", "-help", "--help":
+		return true
+	}
+	return false
+}
+
+func run(args []string, e *env) int {
+	commands := map[string]func([]string, *env) (int, error){
+		"list":      cmdList,
+		"scan":      cmdScan,
+		"check":     cmdCheck,
+		"wait":      cmdWait,
+		"free-port": cmdFreePort,
+		"kill":      cmdKill,
+		"pin":       cmdPin(true),
+		"unpin":     cmdPin(false),
+		"add":       cmdAdd,
+		"remove":    cmdRemove,
+	}
+	// saving saves the config; with a config that failed to load that would
+	// replace the user's file with the defaults.
+	saving := map[string]bool{"pin": true, "unpin": true, "add": true, "remove": true}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(e.stderr, "unknown command %q\n\n%s", args[0], usage)
+		return ExitUsage
+	}
+	if saving[args[0]] && e.configErr != nil {
+		fmt.Fprintf(e.stderr, "portsentinel %s: config could not be loaded (%v); fix or remove it first so it is not overwritten\n", args[0], e.configErr)
+		return ExitFailure
+	}
+	code, err := cmd(args[1:], e)
+	var usageErr usageError
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(e.stderr, "portsentinel %s: %v\nRun 'portsentinel help' for usage.\n", args[0], usageErr)
+		return ExitUsage
+	case err != nil:
+		fmt.Fprintf(e.stderr, "portsentinel %s: %v\n", args[0], err)
+	}
+	return code
+}
+
+func newFlagSet(name string, e *env) *flag.FlagSet {
+	fs := flag.NewFlagSet("portsentinel "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// parseFlags parses fs and returns the positional arguments. Flags may
+// follow positionals ("kill 3000 --yes"). Bad flags are usage errors; flag
+// has already printed the details.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usageErrorf("invalid flags")
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func parsePorts(args []string) ([]int, error) {
+	out := make([]int, 0, len(args))
+	for _, arg := range args {
+		port, err := strconv.Atoi(arg)
+		if err != nil || app.ValidatePort(port) != nil {
+			return nil, usageErrorf("invalid port %q", arg)
+		}
+		out = append(out, port)
+	}
+	return out, nil
+}
+
+func parseOnePort(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, usageErrorf("expected exactly one port")
+	}
+	list, err := parsePorts(args)
+	if err != nil {
+		return 0, err
+	}
+	return list[0], nil
+}
+
+type formatFlags struct {
+	format   *string
+	template *string
+}
+
+func addFormatFlags(fs *flag.FlagSet) formatFlags {
+	return formatFlags{
+		format:   fs.String("format", output.FormatTable, "output format: table, json, ndjson, csv or template"),
+		template: fs.String("template", "", "Go text/template applied to each result; implies --format template"),
+	}
+}
+
+func (f formatFlags) writer(state *app.State) (*output.Writer, error) {
+	format := *f.format
+	if *f.template != "" && format == output.FormatTable {
+		format = output.FormatTemplate
+	}
+	w, err := output.New(output.Options{Format: format, Template: *f.template, Pinned: state.SnapshotConfig().PinnedPorts})
+	if err != nil {
+		return nil, usageErrorf("%v", err)
+	}
+	return w, nil
+}
+
+// writeResults prints results and turns a scan error into exit code 2; the
+// results that could be collected are still printed.
+func writeResults(e *env, w *output.Writer, results []ports.PortScanResult, scanErr error) (int, error) {
+	if err := w.Write(e.stdout, results); err != nil {
+		return ExitFailure, err
+	}
+	if scanErr != nil {
+		return ExitScanError, scanErr
+	}
+	return ExitOK, nil
+}
+
+func cmdList(args []string, e *env) (int, error) {
+	fs := newFlagSet("list", e)
+	formats := addFormatFlags(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage, err
+	}
+	if len(rest) > 0 {
+		return ExitUsage, usageErrorf("list takes no arguments")
+	}
+	w, err := formats.writer(e.state)
+	if err != nil {
+		return ExitUsage, err
+	}
+	// Not RefreshAll: remediation policies act from the GUI refresh loop only,
+	// so a read-only list can never signal a process or run a command.
+	results, err := e.svc.ScanPorts(e.state.GetPorts())
+	e.state.SetResults(results)
+	return writeResults(e, w, e.state.SnapshotResults(), err)
+}
+
+func cmdScan(args []string, e *env) (int, error) {
+	fs := newFlagSet("scan", e)
+	all := fs.Bool("all", false, "scan every listening socket instead of the given ports")
+	formats := addFormatFlags(fs)
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage, err
+	}
+	w, err := formats.writer(e.state)
+	if err != nil {
+		return ExitUsage, err
+	}
+	var results []ports.PortScanResult
+	if *all {
+		if len(rest) > 0 {
+			return ExitUsage, usageErrorf("--all takes no ports")
+		}
+		results, err = e.svc.ScanAllListeners()
+	} else {
+		if len(rest) == 0 {
+			return ExitUsage, usageErrorf("give one or more ports, or --all")
+		}
+		list, parseErr := parsePorts(rest)
+		if parseErr != nil {
+			return ExitUsage, parseErr
+		}
+		results, err = e.svc.ScanPorts(list)
+	}
+	return writeResults(e, w, results, err)
+}
+
+// cmdWait waits for each port in turn; --timeout bounds the whole command.
+// Progress goes to stderr so stdout stays clean for scripts.
+func cmdWait(args []string, e *env) (int, error) {
+	fs := newFlagSet("wait", e)
+	until := fs.String("until", ports.WaitInUse, "condition: in-use, free or healthy (the port's health probe, or a TCP connect)")
+	timeout := fs.Duration("timeout", 0, "give up after this long; 0 waits forever")
+	quiet := fs.Bool("quiet", false, "do not print progress")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage, err
+	}
+	list, err := parsePorts(rest)
+	if err != nil {
+		return ExitUsage, err
+	}
+	if len(list) == 0 {
+		return ExitUsage, usageErrorf("give one or more ports")
+	}
+	switch *until {
+	case ports.WaitInUse, ports.WaitFree, ports.WaitHealthy:
+	default:
+		return ExitUsage, usageErrorf("unknown condition %q (want in-use, free or healthy)", *until)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	// A wait that ends while scans are failing is a scan error, not a timeout.
+	var lastScanErr error
+	opts := ports.WaitOptions{Until: *until, Progress: func(a ports.WaitAttempt) {
+		lastScanErr = a.Err
+		if !*quiet {
+			fmt.Fprintln(e.stderr, a.String())
+		}
+	}}
+	for _, port := range list {
+		lastScanErr = nil
+		if _, err := e.svc.WaitForPort(ctx, port, opts); err != nil {
+			if lastScanErr != nil {
+				return ExitScanError, err
+			}
+			return ExitFailure, err
+		}
+		if !*quiet {
+			fmt.Fprintf(e.stderr, "port %d is %s\n", port, *until)
+		}
+	}
+	return ExitOK, nil
+}
+
+// cmdFreePort prints one port per line. Ports found before running out are
+// still printed.
+func cmdFreePort(args []string, e *env) (int, error) {
+	fs := newFlagSet("free-port", e)
+	near := fs.Int("near", 0, "start the search here and work outwards (default: start of --range)")
+	rangeText := fs.String("range", "", "search only MIN-MAX (default: --near up to 65535)")
+	count := fs.Int("count", 1, "how many ports to find")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage, err
+	}
+	if len(rest) > 0 {
+		return ExitUsage, usageErrorf("free-port takes no arguments")
+	}
+	opts := ports.FreePortOptions{Near: *near, Count: *count}
+	if *near != 0 && app.ValidatePort(*near) != nil {
+		return ExitUsage, usageErrorf("invalid port %d for --near", *near)
+	}
+	if *count < 1 {
+		return ExitUsage, usageErrorf("--count must be at least 1")
+	}
+	if *rangeText != "" {
+		if opts.Range, err = ports.ParsePortRange(*rangeText); err != nil {
+			return ExitUsage, usageErrorf("%v", err)
+		}
+		if *near != 0 && !opts.Range.Contains(*near) {
+			return ExitUsage, usageErrorf("--near %d is outside --range %s", *near, opts.Range)
+		}
+	}
+
+	found, err := e.svc.FindFreePorts(opts)
+	for _, port := range found {
+		fmt.Fprintln(e.stdout, port)
+	}
+	switch {
+	case errors.Is(err, ports.ErrNoFreePort):
+		return ExitFailure, err
+	case err != nil:
+		return ExitScanError, err
+	}
+	return ExitOK, nil
+}
+
+func cmdKill(args []string, e *env) (int, error) {
+	cfg := e.state.SnapshotConfig()
+	fs := newFlagSet("kill", e)
+	sigFlag := fs.String("signal", string(ports.SignalTERM), "signal to send: TERM, INT, HUP, QUIT or KILL")
+	grace := fs.Duration("grace", time.Duration(cfg.UI.KillGracePeriodMs)*time.Millisecond, "how long to wait for the process to exit")
+	escalate := fs.Bool("escalate", cfg.UI.KillEscalate, "send KILL if the process outlives the grace period")
+	yes := fs.Bool("yes", false, "confirm; without it only a preview is printed")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return ExitUsage, err
+	}
+	port, err := parseOnePort(rest)
+	if err != nil {
+		return ExitUsage, err
+	}
+	sig, ok := ports.ParseSignal(strings.TrimPrefix(strings.ToUpper(*sigFlag), "SIG"))
+	if !ok {
+		return ExitUsage, usageErrorf("unknown signal %q", *sigFlag)
+	}
+
+	target, err := e.svc.RefreshOne(port)
+	if err != nil {
+		return ExitScanError, err
+	}
+	if target.Status != ports.StatusInUse || target.PID <= 0 {
+		return ExitFailure, fmt.Errorf("port %d is %s; nothing to terminate", port, target.Status)
+	}
+	if !*yes {
+		fmt.Fprintf(e.stdout, "Port %d is held by PID %d (%s).\n", port, target.PID, firstNonEmpty(output.ProcessLabel(target), "unknown"))
+		decision := e.svc.KillPolicy(target)
+		switch {
+		case !decision.Allowed:
+			fmt.Fprintf(e.stdout, "  Kill policy: refused, %s\n", decision.Reason)
+		case decision.Force:
+			fmt.Fprintf(e.stdout, "  Kill policy: %s; SIGKILL will be sent\n", decision.Reason)
+		default:
+			fmt.Fprintln(e.stdout, "  Kill policy: allowed")
+		}
+		for _, line := range e.svc.KillImpact(target).Lines() {
+			fmt.Fprintln(e.stdout, "  "+line)
+		}
+		if !decision.Allowed {
+			return ExitFailure, fmt.Errorf("PID %d cannot be terminated: %s", target.PID, decision.Reason)
+		}
+		return ExitFailure, errors.New("not terminated; re-run with --yes to confirm")
+	}
+
+	report, err := e.svc.KillProcess(target, app.KillOptions{
+		Signal:      sig,
+		GracePeriod: *grace,
+		Escalate:    *escalate,
+		Source:      app.SourceCLI,
+	})
+	if summary := report.Summary(); summary != "" {
+		fmt.Fprintf(e.stdout, "PID %d: %s\n", target.PID, summary)
+	}
+	if err != nil {
+		return ExitFailure, err
+	}
+	return ExitOK, nil
+}
+
+func cmdPin(pinned bool) func([]string, *env) (int, error) {
+	return func(args []string, e *env) (int, error) {
+		port, err := parseOnePort(args)
+		if err != nil {
+			return ExitUsage, err
+		}
+		if !watched(e.state, port) {
+			return ExitFailure, fmt.Errorf("port %d is not watched; add it first", port)
+		}
+		if err := e.svc.TogglePinAndSave(port, pinned); err != nil {
+			return ExitFailure, err
+		}
+		return ExitOK, nil
+	}
+}
+
+func cmdAdd(args []string, e *env) (int, error) {
+	list, err := parsePorts(args)
+	if err != nil {
+		return ExitUsage, err
+	}
+	if len(list) == 0 {
+		return ExitUsage, usageErrorf("give one or more ports")
+	}
+	for _, port := range list {
+		if err := e.svc.AddCustomPortAndSave(port); err != nil {
+			return ExitFailure, fmt.Errorf("port %d: %w", port, err)
+		}
+	}
+	return ExitOK, nil
+}
+
+func cmdRemove(args []string, e *env) (int, error) {
+	list, err := parsePorts(args)
+	if err != nil {
+		return ExitUsage, err
+	}
+	if len(list) == 0 {
+		return ExitUsage, usageErrorf("give one or more ports")
+	}
+	for _, port := range list {
+		if err := e.svc.RemoveCustomPortAndSave(port); err != nil {
+			return ExitFailure, fmt.Errorf("port %d: %w", port, err)
+		}
+	}
+	return ExitOK, nil
+}
+
+func watched(state *app.State, port int) bool {
+	for _, p := range state.GetPorts() {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, val := range values {
+		if strings.TrimSpace(val) != "" {
+			return val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,326 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"port_sentinel/internal/app"
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/store"
+)
+
+// fakeScanner implements the parts of app.PortScanner the commands reach;
+// anything else panics through the nil embedded interface.
+type fakeScanner struct {
+	app.PortScanner
+	results []ports.PortScanResult
+	scanErr error
+	alive   int
+	signals []ports.Signal
+}
+
+func (f *fakeScanner) ScanPorts(list []int) ([]ports.PortScanResult, error) {
+	out := make([]ports.PortScanResult, 0, len(list))
+	for _, port := range list {
+		res, _ := f.ScanPort(port)
+		out = append(out, res)
+	}
+	return out, f.scanErr
+}
+
+func (f *fakeScanner) ScanPort(port int) (ports.PortScanResult, error) {
+	for _, res := range f.results {
+		if res.Port == port {
+			return res, f.scanErr
+		}
+	}
+	return ports.PortScanResult{Port: port, Status: ports.StatusFree}, f.scanErr
+}
+
+func (f *fakeScanner) ScanAllListeners() ([]ports.PortScanResult, error) {
+	return f.results, f.scanErr
+}
+
+func (f *fakeScanner) ListProcesses() ([]ports.ProcessEntry, error) { return nil, nil }
+
+func (f *fakeScanner) ProcessManager(int, []ports.ProcessEntry) string { return "" }
+
+func (f *fakeScanner) CountConnections(int, int) (int, error) { return 0, nil }
+
+func (f *fakeScanner) ProcessCwd(int) (string, error) { return "", errors.New("unavailable") }
+
+func (f *fakeScanner) DirLastModified(string) (time.Time, error) { return time.Time{}, os.ErrNotExist }
+
+func (f *fakeScanner) BindTest(int) error { return nil }
+
+func (f *fakeScanner) ExcludedPortRanges() []ports.PortRange { return nil }
+
+func (f *fakeScanner) GetProcessInfo(pid int) (ports.ProcessInfo, error) {
+	return ports.ProcessInfo{PID: pid}, nil
+}
+
+func (f *fakeScanner) ProcessExists(int) bool {
+	f.alive--
+	return f.alive >= 0
+}
+
+func (f *fakeScanner) SignalPID(pid int, sig ports.Signal) error {
+	f.signals = append(f.signals, sig)
+	f.results = nil
+	return nil
+}
+
+type fakeRepo struct {
+	app.ConfigRepository
+	saved int
+	audit []store.AuditEntry
+}
+
+func (r *fakeRepo) SaveConfig(store.Config) error {
+	r.saved++
+	return nil
+}
+
+func (r *fakeRepo) AppendAudit(entry store.AuditEntry) error {
+	r.audit = append(r.audit, entry)
+	return nil
+}
+
+func newTestEnv(scanner *fakeScanner, repo *fakeRepo) (*env, *bytes.Buffer, *bytes.Buffer) {
+	cfg := store.DefaultConfig()
+	cfg.PresetPorts = map[int]bool{}
+	cfg.CustomPorts = []int{3000}
+	state := app.NewState(cfg)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	return &env{state: state, svc: app.NewService(state, scanner, repo), stdout: stdout, stderr: stderr}, stdout, stderr
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := Run(nil, &stdout, &stderr); code != ExitUsage || !strings.Contains(stderr.String(), "Commands:") {
+		t.Fatalf("expected usage on stderr and exit 64, got %d: %q", code, stderr.String())
+	}
+	stdout.Reset()
+	if code := Run([]string{"help"}, &stdout, &stderr); code != ExitOK || !strings.Contains(stdout.String(), "Commands:") {
+		t.Fatalf("expected help on stdout and exit 0, got %d", code)
+	}
+
+	e, _, errOut := newTestEnv(&fakeScanner{}, &fakeRepo{})
+	if code := run([]string{"frobnicate"}, e); code != ExitUsage {
+		t.Fatalf("expected unknown command to exit 64, got %d", code)
+	}
+	if code := run([]string{"add", "70000"}, e); code != ExitUsage || !strings.Contains(errOut.String(), `invalid port "70000"`) {
+		t.Fatalf("expected invalid port to exit 64, got %d: %q", code, errOut.String())
+	}
+}
+
+func TestListAndScan(t *testing.T) {
+	scanner := &fakeScanner{results: []ports.PortScanResult{
+		{Port: 3000, Status: ports.StatusInUse, PID: 4242, ProcessName: "node", User: "dev", LocalAddress: "127.0.0.1:3000"},
+	}}
+	e, stdout, _ := newTestEnv(scanner, &fakeRepo{})
+	e.state.TogglePin(3000, true)
+
+	if code := run([]string{"list"}, e); code != ExitOK {
+		t.Fatalf("expected list to succeed, got %d", code)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "PORT") || !strings.Contains(out, "4242") || !strings.Contains(out, "pinned") {
+		t.Fatalf("unexpected list output:\n%s", out)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"scan", "8080"}, e); code != ExitOK || !strings.Contains(stdout.String(), "8080  FREE") {
+		t.Fatalf("unexpected scan result %d:\n%s", code, stdout.String())
+	}
+
+	scanner.scanErr = errors.New("lsof missing")
+	if code := run([]string{"scan", "--all"}, e); code != ExitScanError {
+		t.Fatalf("expected scan errors to exit 2, got %d", code)
+	}
+}
+
+func TestListDoesNotRunRemediation(t *testing.T) {
+	scanner := &fakeScanner{alive: 1, results: []ports.PortScanResult{
+		{Port: 3000, Status: ports.StatusInUse, PID: 4242, ProcessName: "node"},
+	}}
+	repo := &fakeRepo{}
+	e, _, _ := newTestEnv(scanner, repo)
+	cfg := e.state.SnapshotConfig()
+	cfg.Remediation.Policies = []store.RemediationPolicy{{
+		Name:    "kill node",
+		Enabled: true,
+		Match:   store.RemediationMatch{Port: 3000},
+		Actions: []store.RemediationAction{{Type: store.RemediateSignal, Signal: ports.SignalKILL}},
+	}}
+	e.state.UpdateConfig(cfg)
+
+	if code := run([]string{"list"}, e); code != ExitOK {
+		t.Fatalf("expected list to succeed, got %d", code)
+	}
+	if len(scanner.signals) != 0 || len(repo.audit) != 0 {
+		t.Fatalf("expected list to leave processes alone, got signals %v and audit %v", scanner.signals, repo.audit)
+	}
+}
+
+func TestScanFormats(t *testing.T) {
+	scanner := &fakeScanner{results: []ports.PortScanResult{
+		{Port: 3000, Status: ports.StatusInUse, PID: 4242, ProcessName: "node", CommandLine: "node app.js --token=s3cret"},
+	}}
+	e, stdout, _ := newTestEnv(scanner, &fakeRepo{})
+
+	if code := run([]string{"scan", "3000", "--format", "ndjson"}, e); code != ExitOK {
+		t.Fatalf("expected ndjson scan to succeed, got %d", code)
+	}
+	if out := stdout.String(); !strings.HasPrefix(out, `{"port":3000`) || strings.Contains(out, "s3cret") {
+		t.Fatalf("unexpected ndjson output:\n%s", out)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"scan", "3000", "--template", "{{.Port}} {{.PID}}"}, e); code != ExitOK || stdout.String() != "3000 4242\n" {
+		t.Fatalf("unexpected template result %d: %q", code, stdout.String())
+	}
+
+	for _, args := range [][]string{{"list", "--format", "yaml"}, {"scan", "3000", "--template", "{{.Port"}} {
+		if code := run(args, e); code != ExitUsage {
+			t.Fatalf("expected %v to be a usage error, got %d", args, code)
+		}
+	}
+}
+
+func TestKillNeedsConfirmation(t *testing.T) {
+	pid := os.Getpid() + 1000
+	scanner := &fakeScanner{alive: 2, results: []ports.PortScanResult{{Port: 3000, Status: ports.StatusInUse, PID: pid, ProcessName: "node"}}}
+	repo := &fakeRepo{}
+	e, stdout, _ := newTestEnv(scanner, repo)
+	// The fake reports no owner, which the default policy refuses.
+	cfg := e.state.SnapshotConfig()
+	cfg.KillPolicy.Rules = append(cfg.KillPolicy.Rules, store.KillRule{Port: 3000, Action: store.KillAllow})
+	e.state.UpdateConfig(cfg)
+
+	if code := run([]string{"kill", "3000"}, e); code != ExitFailure || len(scanner.signals) != 0 {
+		t.Fatalf("expected a preview without --yes, got %d signals=%v", code, scanner.signals)
+	}
+	if !strings.Contains(stdout.String(), "held by PID") || !strings.Contains(stdout.String(), "Kill policy: allowed") {
+		t.Fatalf("expected a preview, got:\n%s", stdout.String())
+	}
+
+	cfg = e.state.SnapshotConfig()
+	saved := cfg.KillPolicy.Rules
+	cfg.KillPolicy.Rules = append([]store.KillRule{{Port: 3000, Action: store.KillProtect, Reason: "shared dev database"}}, saved...)
+	e.state.UpdateConfig(cfg)
+	stdout.Reset()
+	if code := run([]string{"kill", "3000"}, e); code != ExitFailure || !strings.Contains(stdout.String(), "Kill policy: refused") {
+		t.Fatalf("expected the preview to show the refusal, got %d:\n%s", code, stdout.String())
+	}
+	cfg.KillPolicy.Rules = saved
+	e.state.UpdateConfig(cfg)
+
+	if code := run([]string{"kill", "3000", "--yes", "--signal", "sigint", "--grace", "1s"}, e); code != ExitOK {
+		t.Fatalf("expected kill to succeed, got %d", code)
+	}
+	if len(scanner.signals) != 1 || scanner.signals[0] != ports.SignalINT {
+		t.Fatalf("expected SIGINT, got %v", scanner.signals)
+	}
+	if len(repo.audit) != 1 || repo.audit[0].Source != app.SourceCLI {
+		t.Fatalf("expected the kill to be audited as cli, got %+v", repo.audit)
+	}
+	if code := run([]string{"kill", "3000", "--yes"}, e); code != ExitFailure {
+		t.Fatalf("expected killing a free port to fail, got %d", code)
+	}
+}
+
+func TestAddRemovePin(t *testing.T) {
+	repo := &fakeRepo{}
+	e, _, _ := newTestEnv(&fakeScanner{}, repo)
+
+	if code := run([]string{"pin", "4000"}, e); code != ExitFailure {
+		t.Fatalf("expected pinning an unwatched port to fail, got %d", code)
+	}
+	if code := run([]string{"add", "4000", "4001"}, e); code != ExitOK || repo.saved != 2 {
+		t.Fatalf("expected two saved ports, got %d saved=%d", code, repo.saved)
+	}
+	if code := run([]string{"pin", "4000"}, e); code != ExitOK || !e.state.IsPinned(4000) {
+		t.Fatalf("expected 4000 to be pinned, got %d", code)
+	}
+	if code := run([]string{"unpin", "4000"}, e); code != ExitOK || e.state.IsPinned(4000) {
+		t.Fatalf("expected 4000 to be unpinned, got %d", code)
+	}
+	if code := run([]string{"remove", "4001"}, e); code != ExitOK {
+		t.Fatalf("expected remove to succeed, got %d", code)
+	}
+	if code := run([]string{"remove", "4001"}, e); code != ExitFailure {
+		t.Fatalf("expected removing an unwatched port to fail, got %d", code)
+	}
+}
+
+func TestSavingCommandsRefuseUnloadedConfig(t *testing.T) {
+	repo := &fakeRepo{}
+	e, out, errOut := newTestEnv(&fakeScanner{}, repo)
+	e.configErr = errors.New("invalid character '}'")
+
+	for _, args := range [][]string{{"add", "4000"}, {"remove", "3000"}, {"pin", "3000"}, {"unpin", "3000"}} {
+		if code := run(args, e); code != ExitFailure {
+			t.Fatalf("%v: expected exit 1, got %d", args, code)
+		}
+	}
+	if repo.saved != 0 || !strings.Contains(errOut.String(), "config could not be loaded") {
+		t.Fatalf("expected no save and an explanation, got saved=%d: %q", repo.saved, errOut.String())
+	}
+	if code := run([]string{"list"}, e); code != ExitOK || !strings.Contains(out.String(), "3000") {
+		t.Fatalf("expected list to keep working with the defaults, got %d: %q", code, out.String())
+	}
+}
+
+func TestWait(t *testing.T) {
+	scanner := &fakeScanner{results: []ports.PortScanResult{
+		{Port: 5432, Status: ports.StatusInUse, PID: 77, ProcessName: "postgres"},
+	}}
+	e, stdout, stderr := newTestEnv(scanner, &fakeRepo{})
+
+	if code := run([]string{"wait", "5432", "--until", "in-use"}, e); code != ExitOK {
+		t.Fatalf("expected an in-use port to satisfy wait, got %d", code)
+	}
+	if stdout.Len() != 0 || !strings.Contains(stderr.String(), "port 5432 is in-use") {
+		t.Fatalf("expected progress on stderr only, got %q / %q", stdout.String(), stderr.String())
+	}
+
+	if code := run([]string{"wait", "--until", "free", "--timeout", "20ms", "5432"}, e); code != ExitFailure {
+		t.Fatalf("expected a timeout to exit 1, got %d", code)
+	}
+	scanner.scanErr = errors.New("lsof missing")
+	if code := run([]string{"wait", "--timeout", "20ms", "5432"}, e); code != ExitScanError {
+		t.Fatalf("expected failing scans to exit 2, got %d", code)
+	}
+	if code := run([]string{"wait", "--until", "open", "5432"}, e); code != ExitUsage {
+		t.Fatalf("expected an unknown condition to exit 64, got %d", code)
+	}
+}
+
+func TestFreePort(t *testing.T) {
+	scanner := &fakeScanner{results: []ports.PortScanResult{
+		{Port: 3000, Status: ports.StatusInUse, PID: 4242, ProcessName: "node"},
+	}}
+	e, stdout, _ := newTestEnv(scanner, &fakeRepo{})
+
+	if code := run([]string{"free-port", "--near", "3000", "--range", "3000-3100", "--count", "2"}, e); code != ExitOK {
+		t.Fatalf("expected free-port to succeed, got %d", code)
+	}
+	if got := stdout.String(); got != "3001\n3002\n" {
+		t.Fatalf("unexpected free ports %q", got)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"free-port", "--range", "3000-3001", "--count", "2"}, e); code != ExitFailure || stdout.String() != "3001\n" {
+		t.Fatalf("expected a shortfall to print what was found and exit 1, got %d: %q", code, stdout.String())
+	}
+	for _, args := range [][]string{{"free-port", "--range", "3100-3000"}, {"free-port", "--near", "80", "--range", "3000-3100"}, {"free-port", "--count", "0"}} {
+		if code := run(args, e); code != ExitUsage {
+			t.Fatalf("expected %v to be a usage error, got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Package output renders scan results as a table, JSON, NDJSON, CSV or a
+// user-supplied text/template. Command lines and endpoint details are masked
+// the same way in every format.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"port_sentinel/internal/ports"
+	"port_sentinel/internal/util"
+)
+
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatCSV      = "csv"
+	FormatTemplate = "template"
+)
+
+func Formats() []string {
+	return []string{FormatTable, FormatJSON, FormatNDJSON, FormatCSV, FormatTemplate}
+}
+
+type Options struct {
+	Format string
+	// Template is a text/template executed once per result, for
+	// FormatTemplate. A trailing newline is added when it has none.
+	Template string
+	// Pinned marks ports shown as pinned in the table.
+	Pinned map[int]bool
+}
+
+// Writer renders results in one format. Build it with New so a bad format or
+// template is reported before anything is scanned.
+type Writer struct {
+	opts Options
+	tmpl *template.Template
+}
+
+func New(opts Options) (*Writer, error) {
+	if opts.Format == "" {
+		opts.Format = FormatTable
+	}
+	w := &Writer{opts: opts}
+	switch opts.Format {
+	case FormatTable, FormatJSON, FormatNDJSON, FormatCSV:
+	case FormatTemplate:
+		if strings.TrimSpace(opts.Template) == "" {
+			return nil, errors.New("template format needs a template")
+		}
+		tmpl, err := template.New("result").Funcs(templateFuncs).Parse(opts.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		w.tmpl = tmpl
+	default:
+		return nil, fmt.Errorf("unknown format %q (want %s)", opts.Format, strings.Join(Formats(), ", "))
+	}
+	return w, nil
+}
+
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+func (w *Writer) Write(out io.Writer, results []ports.PortScanResult) error {
+	masked := make([]ports.PortScanResult, 0, len(results))
+	for _, res := range results {
+		masked = append(masked, Mask(res))
+	}
+	switch w.opts.Format {
+	case FormatJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(masked)
+	case FormatNDJSON:
+		enc := json.NewEncoder(out)
+		for _, res := range masked {
+			if err := enc.Encode(res); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV:
+		return writeCSV(out, masked)
+	case FormatTemplate:
+		return w.writeTemplate(out, masked)
+	default:
+		return writeTable(out, masked, w.opts.Pinned)
+	}
+}
+
+// Mask returns a copy of res with secrets removed from the command line,
+// endpoint details, service banner, health probe detail and forwarding
+// target, all of which may echo a URL or flag from the process.
+func Mask(res ports.PortScanResult) ports.PortScanResult {
+	res.CommandLine = util.MaskSensitiveArgs(res.CommandLine)
+	if res.Service != nil {
+		svc := *res.Service
+		svc.Banner = util.MaskSensitiveArgs(svc.Banner)
+		res.Service = &svc
+	}
+	if res.Health != nil {
+		health := *res.Health
+		health.Detail = util.MaskSensitiveArgs(health.Detail)
+		res.Health = &health
+	}
+	if res.Forward != nil {
+		fwd := *res.Forward
+		fwd.Target = util.MaskSensitiveArgs(fwd.Target)
+		fwd.Via = util.MaskSensitiveArgs(fwd.Via)
+		fwd.Resource = util.MaskSensitiveArgs(fwd.Resource)
+		res.Forward = &fwd
+	}
+	if len(res.Endpoints) > 0 {
+		endpoints := make([]ports.Endpoint, 0, len(res.Endpoints))
+		for _, ep := range res.Endpoints {
+			ep.URL = util.MaskSensitiveArgs(ep.URL)
+			ep.Detail = util.MaskSensitiveArgs(ep.Detail)
+			endpoints = append(endpoints, ep)
+		}
+		res.Endpoints = endpoints
+	}
+	return res
+}
+
+var csvHeader = []string{"port", "status", "protocol", "pid", "process", "label", "user", "address", "commandLine", "exePath", "startTime", "error"}
+
+func writeCSV(out io.Writer, results []ports.PortScanResult) error {
+	cw := csv.NewWriter(out)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, res := range results {
+		label := ""
+		if res.Classification != nil {
+			label = res.Classification.Label
+		}
+		started := ""
+		if !res.StartTime.IsZero() {
+			started = res.StartTime.UTC().Format(time.RFC3339)
+		}
+		record := []string{
+			strconv.Itoa(res.Port), string(res.Status), string(res.Protocol), strconv.Itoa(res.PID),
+			res.ProcessName, label, res.User, res.LocalAddress, res.CommandLine, res.ExePath, started, res.Error,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (w *Writer) writeTemplate(out io.Writer, results []ports.PortScanResult) error {
+	for _, res := range results {
+		var b strings.Builder
+		if err := w.tmpl.Execute(&b, res); err != nil {
+			return err
+		}
+		line := b.String()
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		if _, err := io.WriteString(out, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTable(out io.Writer, results []ports.PortScanResult, pinned map[int]bool) error {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PORT\tSTATUS\tPID\tPROCESS\tUSER\tADDRESS\tNOTES")
+	for _, res := range results {
+		pid := "-"
+		if res.PID > 0 {
+			pid = strconv.Itoa(res.PID)
+		}
+		notes := make([]string, 0)
+		if pinned[res.Port] {
+			notes = append(notes, "pinned")
+		}
+		if res.Orphan != nil {
+			notes = append(notes, "likely orphan")
+		}
+		if len(res.Warnings) > 0 {
+			notes = append(notes, fmt.Sprintf("%d warning(s)", len(res.Warnings)))
+		}
+		if res.Error != "" {
+			notes = append(notes, res.Error)
+		}
+		process := "-"
+		if res.PID > 0 {
+			process = firstNonEmpty(ProcessLabel(res), "unknown")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", res.Port, res.Status, pid,
+			process, firstNonEmpty(res.User, "-"), firstNonEmpty(res.LocalAddress, "-"), strings.Join(notes, ", "))
+	}
+	return tw.Flush()
+}
+
+// ProcessLabel is the process name, followed by its classification label
+// when that differs.
+func ProcessLabel(res ports.PortScanResult) string {
+	name := res.ProcessName
+	if label := res.DisplayName(); label != "" && label != name {
+		if name == "" {
+			return label
+		}
+		name = fmt.Sprintf("%s — %s", name, label)
+	}
+	return name
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, val := range values {
+		if strings.TrimSpace(val) != "" {
+			return val
+		}
+	}
+	return ""
+}
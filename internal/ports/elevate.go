@@ -0,0 +1,127 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"port_sentinel/internal/util"
+)
+
+// ErrPermission means the OS refused the operation for the current user;
+// retrying through an elevation helper may succeed.
+var ErrPermission = errors.New("permission denied")
+
+var ErrNoElevation = errors.New("no elevation helper available")
+
+// Elevation helpers. ElevateAuto picks the first one available on this OS.
+const (
+	ElevateAuto      = "auto"
+	ElevatePkexec    = "pkexec"
+	ElevateSudo      = "sudo"
+	ElevateOSAScript = "osascript"
+	ElevateRunAs     = "runas"
+)
+
+// elevatedTimeout leaves room for the user to answer a password prompt.
+const elevatedTimeout = 2 * time.Minute
+
+// ElevationMethods lists the helpers supported on this OS in order of
+// preference.
+func ElevationMethods() []string {
+	switch runtime.GOOS {
+	case "windows":
+		return []string{ElevateRunAs}
+	case "darwin":
+		return []string{ElevateOSAScript, ElevateSudo}
+	default:
+		return []string{ElevatePkexec, ElevateSudo}
+	}
+}
+
+func resolveElevation(method string) (string, error) {
+	if method != "" && method != ElevateAuto {
+		return method, nil
+	}
+	for _, m := range ElevationMethods() {
+		name := m
+		if m == ElevateRunAs {
+			name = "powershell"
+		}
+		if _, err := exec.LookPath(name); err == nil {
+			return m, nil
+		}
+	}
+	return "", ErrNoElevation
+}
+
+// elevatedCommand wraps argv so it runs with administrator rights through
+// method. Only the single command in argv is elevated, never a shell the
+// user controls.
+func elevatedCommand(method string, argv []string) (string, []string, error) {
+	if len(argv) == 0 {
+		return "", nil, errors.New("empty command")
+	}
+	switch method {
+	case ElevatePkexec:
+		// pkexec requires an absolute program path.
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			return "", nil, err
+		}
+		return "pkexec", append([]string{path}, argv[1:]...), nil
+	case ElevateSudo:
+		return "sudo", append([]string{"-n", "--"}, argv...), nil
+	case ElevateOSAScript:
+		quoted := make([]string, 0, len(argv))
+		for _, arg := range argv {
+			quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+		}
+		script := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(strings.Join(quoted, " "))
+		return "osascript", []string{"-e", fmt.Sprintf(`do shell script "%s" with administrator privileges`, script)}, nil
+	case ElevateRunAs:
+		psQuote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+		args := make([]string, 0, len(argv)-1)
+		for _, arg := range argv[1:] {
+			args = append(args, psQuote(arg))
+		}
+		command := "Start-Process -FilePath " + psQuote(argv[0]) + " -Verb RunAs -Wait -WindowStyle Hidden"
+		if len(args) > 0 {
+			command += " -ArgumentList " + strings.Join(args, ",")
+		}
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", command}, nil
+	}
+	return "", nil, fmt.Errorf("unknown elevation method %q", method)
+}
+
+func runElevated(method string, argv ...string) (util.CmdResult, error) {
+	resolved, err := resolveElevation(method)
+	if err != nil {
+		return util.CmdResult{}, err
+	}
+	name, args, err := elevatedCommand(resolved, argv)
+	if err != nil {
+		return util.CmdResult{}, err
+	}
+	res := util.RunCommand(elevatedTimeout, name, args...)
+	if res.Err != nil {
+		if msg := util.CleanOutput(res.Stderr); msg != "" {
+			return res, fmt.Errorf("%s: %w: %s", resolved, res.Err, msg)
+		}
+		return res, fmt.Errorf("%s: %w", resolved, res.Err)
+	}
+	return res, nil
+}
+
+// permissionError maps "not permitted"/"access denied" command output to
+// ErrPermission.
+func permissionError(res util.CmdResult) error {
+	out := strings.ToLower(res.Stderr + " " + res.Stdout)
+	if strings.Contains(out, "not permitted") || strings.Contains(out, "access is denied") || strings.Contains(out, "permission denied") {
+		return fmt.Errorf("%w: %s", ErrPermission, util.CleanOutput(firstNonBlank(res.Stderr, res.Stdout)))
+	}
+	return res.Err
+}
@@ -0,0 +1,46 @@
+package ports
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"port_sentinel/internal/util"
+)
+
+func TestElevatedCommandQuoting(t *testing.T) {
+	name, args, err := elevatedCommand(ElevateSudo, []string{"kill", "-TERM", "42"})
+	if err != nil || name != "sudo" || !reflect.DeepEqual(args, []string{"-n", "--", "kill", "-TERM", "42"}) {
+		t.Fatalf("unexpected sudo command: %s %v (%v)", name, args, err)
+	}
+
+	_, args, err = elevatedCommand(ElevateOSAScript, []string{"kill", "-TERM", `4'2"`})
+	if err != nil {
+		t.Fatalf("osascript: %v", err)
+	}
+	want := `do shell script "'kill' '-TERM' '4'\\''2\"'" with administrator privileges`
+	if args[1] != want {
+		t.Fatalf("unexpected osascript script:\n got %s\nwant %s", args[1], want)
+	}
+
+	_, args, err = elevatedCommand(ElevateRunAs, []string{"taskkill", "/PID", "42", "/T"})
+	if err != nil || !strings.Contains(args[3], "-FilePath 'taskkill'") || !strings.Contains(args[3], "-ArgumentList '/PID','42','/T'") {
+		t.Fatalf("unexpected runas command: %v (%v)", args, err)
+	}
+
+	if _, _, err := elevatedCommand("su", []string{"kill"}); err == nil {
+		t.Fatalf("expected unknown method to be rejected")
+	}
+}
+
+func TestPermissionError(t *testing.T) {
+	res := util.CmdResult{Stderr: "kill: (1) - Operation not permitted", Err: errors.New("exit status 1")}
+	if err := permissionError(res); !errors.Is(err, ErrPermission) {
+		t.Fatalf("expected ErrPermission, got %v", err)
+	}
+	res = util.CmdResult{Stderr: "kill: (99999) - No such process", Err: errors.New("exit status 1")}
+	if err := permissionError(res); errors.Is(err, ErrPermission) {
+		t.Fatalf("expected other errors to pass through, got %v", err)
+	}
+}
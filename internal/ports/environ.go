@@ -0,0 +1,51 @@
+package ports
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrEnvironUnsupported = errors.New("reading another process's environment is only supported on Linux")
+
+type EnvVar struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ReadEnviron returns the environment a process was started with, sorted by
+// key. Reading it usually requires running as the same user as the process.
+func ReadEnviron(pid int) ([]EnvVar, error) {
+	if runtime.GOOS != "linux" {
+		return nil, ErrEnvironUnsupported
+	}
+	if pid <= 0 {
+		return nil, errors.New("invalid pid")
+	}
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "environ"))
+	if err != nil {
+		return nil, err
+	}
+	return parseEnviron(data), nil
+}
+
+func parseEnviron(data []byte) []EnvVar {
+	out := make([]EnvVar, 0)
+	for _, entry := range bytes.Split(data, []byte{0}) {
+		if len(entry) == 0 {
+			continue
+		}
+		key, value, _ := strings.Cut(string(entry), "=")
+		if key == "" {
+			continue
+		}
+		out = append(out, EnvVar{Key: key, Value: value})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
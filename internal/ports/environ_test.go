@@ -0,0 +1,41 @@
+package ports
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestParseEnviron(t *testing.T) {
+	vars := parseEnviron([]byte("PORT=3001\x00NODE_ENV=development\x00EMPTY=\x00=bogus\x00OPTS=a=b\x00"))
+	want := []EnvVar{
+		{Key: "EMPTY"},
+		{Key: "NODE_ENV", Value: "development"},
+		{Key: "OPTS", Value: "a=b"},
+		{Key: "PORT", Value: "3001"},
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("expected %d vars, got %+v", len(want), vars)
+	}
+	for i := range want {
+		if vars[i] != want[i] {
+			t.Fatalf("var %d: expected %+v, got %+v", i, want[i], vars[i])
+		}
+	}
+}
+
+func TestReadEnvironOwnProcess(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("environ is read from /proc")
+	}
+	t.Setenv("PORTSENTINEL_ENV_TEST", "yes")
+	vars, err := ReadEnviron(os.Getpid())
+	if err != nil {
+		t.Fatalf("ReadEnviron failed: %v", err)
+	}
+	// /proc/<pid>/environ reflects the initial environment, so only check
+	// that something sensible came back.
+	if len(vars) == 0 {
+		t.Fatalf("expected environment variables for own process")
+	}
+}
@@ -0,0 +1,373 @@
+package ports
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ServiceHTTP      = "http"
+	ServiceTLS       = "tls"
+	ServiceRedis     = "redis"
+	ServicePostgres  = "postgres"
+	ServiceMySQL     = "mysql"
+	ServiceAMQP      = "amqp"
+	ServiceMongoDB   = "mongodb"
+	ServiceMemcached = "memcached"
+	ServiceSSH       = "ssh"
+	ServiceUnknown   = "unknown"
+)
+
+type ServiceFingerprint struct {
+	Protocol string `json:"protocol"`
+	Banner   string `json:"banner"`
+}
+
+type serviceProbe struct {
+	protocol string
+	run      func(conn net.Conn) (string, bool)
+}
+
+var serviceProbes = []serviceProbe{
+	// Server-speaks-first protocols are checked before anything is sent.
+	{protocol: "greeting", run: probeGreeting},
+	{protocol: ServiceTLS, run: probeTLS},
+	{protocol: ServiceHTTP, run: probeHTTP},
+	{protocol: ServiceRedis, run: probeRedis},
+	{protocol: ServicePostgres, run: probePostgres},
+	{protocol: ServiceMongoDB, run: probeMongo},
+	{protocol: ServiceAMQP, run: probeAMQP},
+	{protocol: ServiceMemcached, run: probeMemcached},
+}
+
+var wellKnownServices = map[int]string{
+	80:    ServiceHTTP,
+	443:   ServiceTLS,
+	3000:  ServiceHTTP,
+	3306:  ServiceMySQL,
+	4200:  ServiceHTTP,
+	5000:  ServiceHTTP,
+	5173:  ServiceHTTP,
+	5432:  ServicePostgres,
+	5672:  ServiceAMQP,
+	6379:  ServiceRedis,
+	8000:  ServiceHTTP,
+	8080:  ServiceHTTP,
+	8443:  ServiceTLS,
+	9229:  ServiceHTTP,
+	11211: ServiceMemcached,
+	15672: ServiceHTTP,
+	27017: ServiceMongoDB,
+}
+
+// ExpectedService returns the protocol conventionally served on port, or ""
+// when the port has no well-known owner.
+func ExpectedService(port int) string {
+	return wellKnownServices[port]
+}
+
+// DialAddress turns a listener's local address into something we can connect
+// to, mapping wildcard binds to loopback.
+func DialAddress(localAddress string, port int) string {
+	host := "127.0.0.1"
+	addr := strings.TrimSpace(localAddress)
+	if idx := strings.LastIndex(addr, ":"); idx > 0 {
+		h := strings.Trim(addr[:idx], "[]")
+		switch h {
+		case "", "*", "0.0.0.0", "::", "::0":
+		default:
+			host = h
+		}
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// FingerprintService actively probes addr, trying the protocol conventionally
+// expected on port first. Each probe uses its own connection and timeout; the
+// whole pass is bounded to a few multiples of timeout.
+func FingerprintService(addr string, port int, timeout time.Duration) ServiceFingerprint {
+	deadline := time.Now().Add(4 * timeout)
+	for _, probe := range orderedProbes(ExpectedService(port)) {
+		if time.Now().After(deadline) {
+			break
+		}
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return ServiceFingerprint{Protocol: ServiceUnknown, Banner: err.Error()}
+		}
+		wait := timeout
+		if probe.protocol == "greeting" && wait > 500*time.Millisecond {
+			wait = 500 * time.Millisecond
+		}
+		_ = conn.SetDeadline(time.Now().Add(wait))
+		banner, ok := probe.run(conn)
+		conn.Close()
+		if !ok {
+			continue
+		}
+		protocol := probe.protocol
+		if protocol == "greeting" {
+			protocol, banner = splitGreeting(banner)
+		}
+		return ServiceFingerprint{Protocol: protocol, Banner: banner}
+	}
+	return ServiceFingerprint{Protocol: ServiceUnknown}
+}
+
+func orderedProbes(hint string) []serviceProbe {
+	out := make([]serviceProbe, 0, len(serviceProbes))
+	for _, probe := range serviceProbes {
+		if probe.protocol == hint {
+			out = append(out, probe)
+		}
+	}
+	for _, probe := range serviceProbes {
+		if probe.protocol != hint {
+			out = append(out, probe)
+		}
+	}
+	return out
+}
+
+func splitGreeting(banner string) (string, string) {
+	protocol, rest, _ := strings.Cut(banner, "|")
+	return protocol, rest
+}
+
+func probeGreeting(conn net.Conn) (string, bool) {
+	buf := make([]byte, 512)
+	n, _ := conn.Read(buf)
+	if n == 0 {
+		return "", false
+	}
+	data := buf[:n]
+	if bytes.HasPrefix(data, []byte("SSH-")) {
+		line, _, _ := strings.Cut(string(data), "\n")
+		return ServiceSSH + "|" + strings.TrimSpace(line), true
+	}
+	// MySQL handshake: 3-byte length, sequence 0, then protocol 10 and a
+	// NUL-terminated version string (or 0xff for an error packet).
+	if len(data) > 5 && data[3] == 0 {
+		switch data[4] {
+		case 0x0a:
+			version, _, ok := bytes.Cut(data[5:], []byte{0})
+			if ok {
+				return ServiceMySQL + "|" + string(version), true
+			}
+		case 0xff:
+			if len(data) > 7 {
+				msg := strings.TrimLeft(string(data[7:]), "#0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+				return ServiceMySQL + "|error: " + strings.TrimSpace(msg), true
+			}
+		}
+	}
+	return "", false
+}
+
+func probeTLS(conn net.Conn) (string, bool) {
+	client := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, ServerName: "localhost"})
+	if err := client.Handshake(); err != nil {
+		return "", false
+	}
+	state := client.ConnectionState()
+	banner := tls.VersionName(state.Version)
+	if state.NegotiatedProtocol != "" {
+		banner += " (ALPN " + state.NegotiatedProtocol + ")"
+	}
+	return banner, true
+}
+
+func probeHTTP(conn net.Conn) (string, bool) {
+	if _, err := io.WriteString(conn, "GET / HTTP/1.0\r\nHost: localhost\r\nUser-Agent: PortSentinel\r\n\r\n"); err != nil {
+		return "", false
+	}
+	reader := bufio.NewReader(conn)
+	statusLine, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(statusLine, "HTTP/") {
+		return "", false
+	}
+	banner := strings.TrimSpace(statusLine)
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if err != nil || line == "" {
+			break
+		}
+		if name, val, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "server") {
+			banner += " (" + strings.TrimSpace(val) + ")"
+			break
+		}
+	}
+	return banner, true
+}
+
+func probeRedis(conn net.Conn) (string, bool) {
+	if _, err := io.WriteString(conn, "*1\r\n$4\r\nPING\r\n"); err != nil {
+		return "", false
+	}
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", false
+	}
+	line = strings.TrimSpace(line)
+	switch {
+	case line == "+PONG":
+	case strings.HasPrefix(line, "-NOAUTH"), strings.HasPrefix(line, "-DENIED"):
+		return "Redis (auth required)", true
+	default:
+		return "", false
+	}
+	if _, err := io.WriteString(conn, "*2\r\n$4\r\nINFO\r\n$6\r\nserver\r\n"); err != nil {
+		return "Redis", true
+	}
+	header, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(header, "$") {
+		return "Redis", true
+	}
+	size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+	if err != nil || size <= 0 || size > 64*1024 {
+		return "Redis", true
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return "Redis", true
+	}
+	for _, row := range strings.Split(string(body), "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(row), "redis_version:"); ok {
+			return "Redis " + v, true
+		}
+	}
+	return "Redis", true
+}
+
+func probePostgres(conn net.Conn) (string, bool) {
+	// SSLRequest: length 8, magic code 80877103.
+	if _, err := conn.Write([]byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}); err != nil {
+		return "", false
+	}
+	buf := make([]byte, 16)
+	n, _ := conn.Read(buf)
+	if n != 1 {
+		return "", false
+	}
+	switch buf[0] {
+	case 'S':
+		return "PostgreSQL (SSL available)", true
+	case 'N':
+		return "PostgreSQL (SSL not enabled)", true
+	}
+	return "", false
+}
+
+func probeMongo(conn net.Conn) (string, bool) {
+	if _, err := conn.Write(mongoHelloMessage()); err != nil {
+		return "", false
+	}
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", false
+	}
+	length := int(binary.LittleEndian.Uint32(header[0:4]))
+	opCode := binary.LittleEndian.Uint32(header[12:16])
+	if opCode != 2013 || length < 16 || length > 1<<20 {
+		return "", false
+	}
+	body := make([]byte, length-16)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return "MongoDB", true
+	}
+	key := append([]byte{0x10}, []byte("maxWireVersion\x00")...)
+	if idx := bytes.Index(body, key); idx >= 0 && idx+len(key)+4 <= len(body) {
+		wire := binary.LittleEndian.Uint32(body[idx+len(key):])
+		return fmt.Sprintf("MongoDB (wire version %d)", wire), true
+	}
+	return "MongoDB", true
+}
+
+// mongoHelloMessage builds an OP_MSG carrying {hello: 1, $db: "admin"}.
+func mongoHelloMessage() []byte {
+	var doc bytes.Buffer
+	doc.Write([]byte{0, 0, 0, 0})
+	doc.WriteByte(0x10)
+	doc.WriteString("hello\x00")
+	_ = binary.Write(&doc, binary.LittleEndian, int32(1))
+	doc.WriteByte(0x02)
+	doc.WriteString("$db\x00")
+	_ = binary.Write(&doc, binary.LittleEndian, int32(len("admin")+1))
+	doc.WriteString("admin\x00")
+	doc.WriteByte(0)
+	docBytes := doc.Bytes()
+	binary.LittleEndian.PutUint32(docBytes[0:4], uint32(len(docBytes)))
+
+	var msg bytes.Buffer
+	_ = binary.Write(&msg, binary.LittleEndian, int32(16+4+1+len(docBytes)))
+	_ = binary.Write(&msg, binary.LittleEndian, int32(1))
+	_ = binary.Write(&msg, binary.LittleEndian, int32(0))
+	_ = binary.Write(&msg, binary.LittleEndian, int32(2013))
+	_ = binary.Write(&msg, binary.LittleEndian, uint32(0))
+	msg.WriteByte(0)
+	msg.Write(docBytes)
+	return msg.Bytes()
+}
+
+func probeAMQP(conn net.Conn) (string, bool) {
+	if _, err := conn.Write([]byte("AMQP\x00\x00\x09\x01")); err != nil {
+		return "", false
+	}
+	buf := make([]byte, 4096)
+	n, _ := io.ReadAtLeast(conn, buf, 8)
+	data := buf[:n]
+	if bytes.HasPrefix(data, []byte("AMQP")) {
+		// The server rejected our version and replied with the one it speaks.
+		return "AMQP (version mismatch)", true
+	}
+	// Connection.Start method frame: type 1, channel 0, class 10, method 10.
+	if n < 11 || data[0] != 1 || binary.BigEndian.Uint16(data[7:9]) != 10 || binary.BigEndian.Uint16(data[9:11]) != 10 {
+		return "", false
+	}
+	product := amqpTableString(data, "product")
+	version := amqpTableString(data, "version")
+	banner := strings.TrimSpace(product + " " + version)
+	if banner == "" {
+		banner = "AMQP 0-9-1"
+	}
+	return banner, true
+}
+
+func amqpTableString(data []byte, key string) string {
+	needle := append([]byte{byte(len(key))}, key...)
+	needle = append(needle, 'S')
+	idx := bytes.Index(data, needle)
+	if idx < 0 || idx+len(needle)+4 > len(data) {
+		return ""
+	}
+	start := idx + len(needle) + 4
+	size := int(binary.BigEndian.Uint32(data[idx+len(needle) : start]))
+	if size < 0 || start+size > len(data) {
+		return ""
+	}
+	return string(data[start : start+size])
+}
+
+func probeMemcached(conn net.Conn) (string, bool) {
+	if _, err := io.WriteString(conn, "version\r\n"); err != nil {
+		return "", false
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", false
+	}
+	if v, ok := strings.CutPrefix(strings.TrimSpace(line), "VERSION "); ok {
+		return "memcached " + v, true
+	}
+	return "", false
+}
@@ -0,0 +1,112 @@
+package ports
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveOnce(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+				handle(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestFingerprintServiceDetectsHTTPOnPostgresPort(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Server", "squatter/1.0")
+	}))
+	defer srv.Close()
+
+	fp := FingerprintService(strings.TrimPrefix(srv.URL, "http://"), 5432, 300*time.Millisecond)
+	if fp.Protocol != ServiceHTTP {
+		t.Fatalf("expected http, got %+v", fp)
+	}
+	if !strings.Contains(fp.Banner, "squatter/1.0") {
+		t.Fatalf("expected server header in banner, got %q", fp.Banner)
+	}
+}
+
+func TestFingerprintServiceRedis(t *testing.T) {
+	addr := serveOnce(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch strings.TrimSpace(line) {
+			case "PING":
+				conn.Write([]byte("+PONG\r\n"))
+			case "server":
+				body := "# Server\r\nredis_version:7.2.4\r\n"
+				conn.Write([]byte("$" + strconv.Itoa(len(body)) + "\r\n" + body + "\r\n"))
+			}
+		}
+	})
+
+	fp := FingerprintService(addr, 6379, 300*time.Millisecond)
+	if fp.Protocol != ServiceRedis || fp.Banner != "Redis 7.2.4" {
+		t.Fatalf("expected redis 7.2.4, got %+v", fp)
+	}
+}
+
+func TestFingerprintServicePostgresAndMySQL(t *testing.T) {
+	pg := serveOnce(t, func(conn net.Conn) {
+		buf := make([]byte, 8)
+		if n, _ := conn.Read(buf); n == 8 && buf[4] == 0x04 {
+			conn.Write([]byte("N"))
+		}
+	})
+	if fp := FingerprintService(pg, 5432, 300*time.Millisecond); fp.Protocol != ServicePostgres {
+		t.Fatalf("expected postgres, got %+v", fp)
+	}
+
+	greeting := append([]byte{0x0e, 0, 0, 0, 0x0a}, []byte("8.0.36\x00abcdefgh")...)
+	my := serveOnce(t, func(conn net.Conn) {
+		conn.Write(greeting)
+		buf := make([]byte, 64)
+		conn.Read(buf)
+	})
+	if fp := FingerprintService(my, 3306, 300*time.Millisecond); fp.Protocol != ServiceMySQL || fp.Banner != "8.0.36" {
+		t.Fatalf("expected mysql 8.0.36, got %+v", fp)
+	}
+}
+
+func TestDialAddressMapsWildcardsToLoopback(t *testing.T) {
+	cases := map[string]string{
+		"*:3000":         "127.0.0.1:3000",
+		"0.0.0.0:3000":   "127.0.0.1:3000",
+		"[::]:3000":      "127.0.0.1:3000",
+		"[::1]:3000":     "[::1]:3000",
+		"127.0.0.2:3000": "127.0.0.2:3000",
+		"":               "127.0.0.1:3000",
+	}
+	for in, want := range cases {
+		if got := DialAddress(in, 3000); got != want {
+			t.Fatalf("DialAddress(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package ports
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type ForwardInfo struct {
+	Tool      string `json:"tool"`
+	Target    string `json:"target"`
+	Via       string `json:"via"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace"`
+}
+
+func (f ForwardInfo) Summary() string {
+	if f.Tool == "kubectl" {
+		out := "k8s " + f.Resource
+		if f.Namespace != "" {
+			out += " in namespace " + f.Namespace
+		}
+		if f.Target != "" {
+			out += " (remote port " + f.Target + ")"
+		}
+		return out
+	}
+	out := "forward → " + f.Target
+	if f.Via != "" {
+		out += " via " + f.Via
+	}
+	return out
+}
+
+// DescribeForward recognises well-known forwarding listeners (ssh -L,
+// kubectl port-forward, docker-proxy, socat) and extracts what they forward
+// the given local port to.
+func DescribeForward(processName, commandLine string, port int) (ForwardInfo, bool) {
+	args := strings.Fields(commandLine)
+	if len(args) == 0 {
+		return ForwardInfo{}, false
+	}
+	tool := toolName(processName)
+	if tool == "" {
+		tool = toolName(args[0])
+	}
+	switch tool {
+	case "ssh":
+		return describeSSH(args[1:], port)
+	case "kubectl":
+		return describeKubectl(args[1:], port)
+	case "docker-proxy":
+		return describeDockerProxy(args[1:], port)
+	case "socat":
+		return describeSocat(args[1:], port)
+	}
+	return ForwardInfo{}, false
+}
+
+func toolName(name string) string {
+	name = strings.ToLower(filepath.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/")))
+	name = strings.TrimSuffix(name, ".exe")
+	switch name {
+	case "ssh", "kubectl", "docker-proxy", "socat":
+		return name
+	}
+	return ""
+}
+
+// sshOptionsWithArg lists ssh flags that consume the following argument.
+const sshOptionsWithArg = "BbcDEeFIiJLlmOopQRSWw"
+
+func describeSSH(args []string, port int) (ForwardInfo, bool) {
+	var forwards []string
+	var dynamic []string
+	user := ""
+	dest := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if dest != "" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			dest = arg
+			continue
+		}
+		if arg == "--" {
+			if i+1 < len(args) {
+				dest = args[i+1]
+			}
+			break
+		}
+		// Flags may be bundled ("-fNL 5432:db:5432") so walk each character.
+		for j := 1; j < len(arg); j++ {
+			opt := arg[j]
+			if !strings.ContainsRune(sshOptionsWithArg, rune(opt)) {
+				continue
+			}
+			val := arg[j+1:]
+			if val == "" && i+1 < len(args) {
+				i++
+				val = args[i]
+			}
+			switch opt {
+			case 'L':
+				forwards = append(forwards, val)
+			case 'D':
+				dynamic = append(dynamic, val)
+			case 'l':
+				user = val
+			}
+			break
+		}
+	}
+	if dest == "" {
+		return ForwardInfo{}, false
+	}
+	if user != "" && !strings.Contains(dest, "@") {
+		dest = user + "@" + dest
+	}
+	via := "ssh " + dest
+	for _, spec := range forwards {
+		listenPort, target, ok := parseSSHForward(spec)
+		if ok && listenPort == port {
+			return ForwardInfo{Tool: "ssh", Target: target, Via: via}, true
+		}
+	}
+	for _, spec := range dynamic {
+		if parsePortFromAddress(spec) == port {
+			return ForwardInfo{Tool: "ssh", Target: "SOCKS proxy", Via: via}, true
+		}
+	}
+	return ForwardInfo{}, false
+}
+
+// parseSSHForward handles "[bind:]port:host:hostport", including bracketed
+// IPv6 hosts.
+func parseSSHForward(spec string) (int, string, bool) {
+	parts := splitHostPortList(spec)
+	if len(parts) < 3 {
+		return 0, "", false
+	}
+	n := len(parts)
+	listenPort, err := strconv.Atoi(parts[n-3])
+	if err != nil {
+		return 0, "", false
+	}
+	return listenPort, net.JoinHostPort(parts[n-2], parts[n-1]), true
+}
+
+func splitHostPortList(spec string) []string {
+	var parts []string
+	var cur strings.Builder
+	inBracket := false
+	for _, r := range spec {
+		switch {
+		case r == '[':
+			inBracket = true
+		case r == ']':
+			inBracket = false
+		case r == ':' && !inBracket:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	return append(parts, cur.String())
+}
+
+func describeKubectl(args []string, port int) (ForwardInfo, bool) {
+	if len(args) == 0 {
+		return ForwardInfo{}, false
+	}
+	namespace := ""
+	var positional []string
+	sawPortForward := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-n" || arg == "--namespace":
+			if i+1 < len(args) {
+				i++
+				namespace = args[i]
+			}
+		case strings.HasPrefix(arg, "--namespace="):
+			namespace = strings.TrimPrefix(arg, "--namespace=")
+		case strings.HasPrefix(arg, "-n") && len(arg) > 2 && !strings.HasPrefix(arg, "--"):
+			namespace = strings.TrimPrefix(arg[2:], "=")
+		case arg == "--address" || arg == "--context" || arg == "--kubeconfig" || arg == "--pod-running-timeout":
+			i++
+		case strings.HasPrefix(arg, "-"):
+		case arg == "port-forward" && !sawPortForward:
+			sawPortForward = true
+		default:
+			if sawPortForward {
+				positional = append(positional, arg)
+			}
+		}
+	}
+	if !sawPortForward || len(positional) < 2 {
+		return ForwardInfo{}, false
+	}
+	resource := positional[0]
+	if !strings.Contains(resource, "/") {
+		resource = "pod/" + resource
+	}
+	for _, spec := range positional[1:] {
+		local, remote := spec, spec
+		if idx := strings.Index(spec, ":"); idx >= 0 {
+			local, remote = spec[:idx], spec[idx+1:]
+		}
+		if local == "" {
+			// ":80" lets kubectl pick a random local port; we cannot tell
+			// which one from the command line, so accept any.
+			local = strconv.Itoa(port)
+		}
+		if local == strconv.Itoa(port) {
+			return ForwardInfo{Tool: "kubectl", Target: remote, Resource: resource, Namespace: namespace}, true
+		}
+	}
+	return ForwardInfo{}, false
+}
+
+func describeDockerProxy(args []string, port int) (ForwardInfo, bool) {
+	values := map[string]string{}
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimLeft(args[i], "-")
+		if arg == args[i] {
+			continue
+		}
+		if idx := strings.Index(arg, "="); idx >= 0 {
+			values[arg[:idx]] = arg[idx+1:]
+			continue
+		}
+		if i+1 < len(args) {
+			i++
+			values[arg] = args[i]
+		}
+	}
+	hostPort, _ := strconv.Atoi(values["host-port"])
+	if hostPort != port || values["container-ip"] == "" {
+		return ForwardInfo{}, false
+	}
+	target := values["container-ip"]
+	if values["container-port"] != "" {
+		target = net.JoinHostPort(target, values["container-port"])
+	}
+	return ForwardInfo{Tool: "docker-proxy", Target: target, Via: "docker-proxy"}, true
+}
+
+func describeSocat(args []string, port int) (ForwardInfo, bool) {
+	var addrs []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			continue
+		}
+		addrs = append(addrs, arg)
+	}
+	if len(addrs) != 2 {
+		return ForwardInfo{}, false
+	}
+	for i, addr := range addrs {
+		kind, rest := splitSocatAddress(addr)
+		if !strings.HasSuffix(kind, "-LISTEN") && !strings.HasSuffix(kind, "-L") {
+			continue
+		}
+		listenPort, _ := strconv.Atoi(rest)
+		if listenPort != port {
+			continue
+		}
+		_, target := splitSocatAddress(addrs[1-i])
+		if target == "" {
+			target = addrs[1-i]
+		}
+		return ForwardInfo{Tool: "socat", Target: target, Via: "socat"}, true
+	}
+	return ForwardInfo{}, false
+}
+
+// splitSocatAddress turns "TCP-LISTEN:8080,fork" into ("TCP-LISTEN", "8080").
+func splitSocatAddress(addr string) (string, string) {
+	addr = strings.Trim(addr, "'\"")
+	if idx := strings.Index(addr, ","); idx >= 0 {
+		addr = addr[:idx]
+	}
+	idx := strings.Index(addr, ":")
+	if idx < 0 {
+		return strings.ToUpper(addr), ""
+	}
+	return strings.ToUpper(addr[:idx]), addr[idx+1:]
+}
@@ -0,0 +1,53 @@
+package ports
+
+import "testing"
+
+func TestDescribeForwardSSH(t *testing.T) {
+	fwd, ok := DescribeForward("ssh", "ssh -N -L 5432:db.internal:5432 user@bastion", 5432)
+	if !ok {
+		t.Fatalf("expected ssh forward to be recognised")
+	}
+	if got := fwd.Summary(); got != "forward → db.internal:5432 via ssh user@bastion" {
+		t.Fatalf("unexpected summary: %q", got)
+	}
+
+	fwd, ok = DescribeForward("ssh", "ssh -fNL127.0.0.1:8080:[::1]:80 -l deploy -p 2222 jump", 8080)
+	if !ok || fwd.Target != "[::1]:80" || fwd.Via != "ssh deploy@jump" {
+		t.Fatalf("expected bundled -L with bind address, got %+v ok=%v", fwd, ok)
+	}
+
+	if _, ok := DescribeForward("ssh", "ssh -L 5432:db:5432 bastion", 6379); ok {
+		t.Fatalf("expected no match for a port the ssh process does not forward")
+	}
+}
+
+func TestDescribeForwardKubectl(t *testing.T) {
+	fwd, ok := DescribeForward("kubectl", "kubectl port-forward -n x svc/api 8080:80", 8080)
+	if !ok {
+		t.Fatalf("expected kubectl forward to be recognised")
+	}
+	if got := fwd.Summary(); got != "k8s svc/api in namespace x (remote port 80)" {
+		t.Fatalf("unexpected summary: %q", got)
+	}
+
+	fwd, ok = DescribeForward("", "/usr/local/bin/kubectl --namespace=dev port-forward web-0 3000", 3000)
+	if !ok || fwd.Resource != "pod/web-0" || fwd.Namespace != "dev" || fwd.Target != "3000" {
+		t.Fatalf("unexpected kubectl forward: %+v ok=%v", fwd, ok)
+	}
+}
+
+func TestDescribeForwardDockerProxyAndSocat(t *testing.T) {
+	fwd, ok := DescribeForward("docker-proxy", "/usr/bin/docker-proxy -proto tcp -host-ip 0.0.0.0 -host-port 8080 -container-ip 172.17.0.2 -container-port 80", 8080)
+	if !ok || fwd.Summary() != "forward → 172.17.0.2:80 via docker-proxy" {
+		t.Fatalf("unexpected docker-proxy forward: %+v ok=%v", fwd, ok)
+	}
+
+	fwd, ok = DescribeForward("socat", "socat TCP-LISTEN:9000,fork,reuseaddr TCP:redis.local:6379", 9000)
+	if !ok || fwd.Summary() != "forward → redis.local:6379 via socat" {
+		t.Fatalf("unexpected socat forward: %+v ok=%v", fwd, ok)
+	}
+
+	if _, ok := DescribeForward("node", "node server.js -L 3000:x:1", 3000); ok {
+		t.Fatalf("expected non-forwarding tools to be ignored")
+	}
+}
@@ -0,0 +1,182 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// PortRange is an inclusive range of ports.
+type PortRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// DefaultEphemeralRange is the IANA dynamic range, used when the system's
+// cannot be read.
+var DefaultEphemeralRange = PortRange{Min: 49152, Max: 65535}
+
+func (r PortRange) Valid() bool {
+	return r.Min >= 1 && r.Max <= 65535 && r.Min <= r.Max
+}
+
+func (r PortRange) Contains(port int) bool {
+	return port >= r.Min && port <= r.Max
+}
+
+func (r PortRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// ParsePortRange accepts "3000-3100" or a single port.
+func ParsePortRange(text string) (PortRange, error) {
+	lo, hi, found := strings.Cut(strings.TrimSpace(text), "-")
+	if !found {
+		hi = lo
+	}
+	min, err1 := strconv.Atoi(strings.TrimSpace(lo))
+	max, err2 := strconv.Atoi(strings.TrimSpace(hi))
+	r := PortRange{Min: min, Max: max}
+	if err1 != nil || err2 != nil || !r.Valid() {
+		return PortRange{}, fmt.Errorf("invalid port range %q", text)
+	}
+	return r, nil
+}
+
+// ErrNoFreePort is returned, wrapped, when fewer free ports than requested
+// were found.
+var ErrNoFreePort = errors.New("not enough free ports")
+
+// FreePortOptions configures FindFreePorts. The zero value finds one port
+// from 1024 upward.
+type FreePortOptions struct {
+	// Near is where the search starts; candidates are tried by distance from
+	// it, higher ports first on a tie. Zero means Range.Min.
+	Near int
+	// Range limits the search; zero means Near (or 1024) to 65535.
+	Range PortRange
+	Count int
+	// Exclude lists ports to skip in addition to the system's ephemeral and
+	// reserved ranges.
+	Exclude []PortRange
+	// Scan, Bind and SystemRanges replace ScanPorts, BindTest and
+	// ExcludedPortRanges, e.g. for tests.
+	Scan         func(ports []int) ([]PortScanResult, error)
+	Bind         func(port int) error
+	SystemRanges func() []PortRange
+}
+
+// FindFreePorts returns up to Count ports that no process listens on, that
+// lie outside the ephemeral and reserved ranges, and that could actually be
+// bound. It scans all candidates once and bind-tests them in order. When
+// fewer are found, the ones found are returned with ErrNoFreePort.
+func FindFreePorts(opts FreePortOptions) ([]int, error) {
+	if opts.Count <= 0 {
+		opts.Count = 1
+	}
+	if opts.Range == (PortRange{}) {
+		opts.Range = PortRange{Min: 1024, Max: 65535}
+		if opts.Near > 0 {
+			opts.Range.Min = opts.Near
+		}
+	}
+	if !opts.Range.Valid() {
+		return nil, fmt.Errorf("invalid port range %s", opts.Range)
+	}
+	if opts.Near == 0 {
+		opts.Near = opts.Range.Min
+	}
+	if !opts.Range.Contains(opts.Near) {
+		return nil, fmt.Errorf("port %d is outside %s", opts.Near, opts.Range)
+	}
+	if opts.Scan == nil {
+		opts.Scan = ScanPorts
+	}
+	if opts.Bind == nil {
+		opts.Bind = BindTest
+	}
+	if opts.SystemRanges == nil {
+		opts.SystemRanges = ExcludedPortRanges
+	}
+
+	excluded := append(opts.SystemRanges(), opts.Exclude...)
+	candidates := make([]int, 0)
+	for _, port := range candidatesNear(opts.Near, opts.Range) {
+		if !inRanges(port, excluded) {
+			candidates = append(candidates, port)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: every port in %s is in an ephemeral, reserved or excluded range", ErrNoFreePort, opts.Range)
+	}
+	results, err := opts.Scan(candidates)
+	if err != nil {
+		return nil, err
+	}
+	free := make(map[int]bool, len(results))
+	for _, res := range results {
+		free[res.Port] = res.Status == StatusFree
+	}
+
+	out := make([]int, 0, opts.Count)
+	for _, port := range candidates {
+		if len(out) == opts.Count {
+			break
+		}
+		if free[port] && opts.Bind(port) == nil {
+			out = append(out, port)
+		}
+	}
+	if len(out) < opts.Count {
+		return out, fmt.Errorf("%w: found %d of %d in %s", ErrNoFreePort, len(out), opts.Count, opts.Range)
+	}
+	return out, nil
+}
+
+// candidatesNear orders r by distance from near: near, near+1, near-1, ...
+func candidatesNear(near int, r PortRange) []int {
+	out := make([]int, 0, r.Max-r.Min+1)
+	for d := 0; near+d <= r.Max || near-d >= r.Min; d++ {
+		if near+d <= r.Max {
+			out = append(out, near+d)
+		}
+		if d > 0 && near-d >= r.Min {
+			out = append(out, near-d)
+		}
+	}
+	return out
+}
+
+func inRanges(port int, ranges []PortRange) bool {
+	for _, r := range ranges {
+		if r.Contains(port) {
+			return true
+		}
+	}
+	return false
+}
+
+// BindTest reports whether a TCP listener can be opened on port, on loopback
+// and on all interfaces. The listeners are closed again right away.
+func BindTest(port int) error {
+	for _, host := range []string{"127.0.0.1", ""} {
+		ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return err
+		}
+		ln.Close()
+	}
+	return nil
+}
+
+// ExcludedPortRanges returns the system's ephemeral range and any ports
+// reserved from it. Ephemeral ports are handed out for outgoing connections,
+// so a server bound there may collide later.
+func ExcludedPortRanges() []PortRange {
+	return append([]PortRange{EphemeralRange()}, ReservedPortRanges()...)
+}
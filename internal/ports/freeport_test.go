@@ -0,0 +1,88 @@
+package ports
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func fakeFreeScan(held ...int) func([]int) ([]PortScanResult, error) {
+	return func(list []int) ([]PortScanResult, error) {
+		out := make([]PortScanResult, 0, len(list))
+		for _, port := range list {
+			status := StatusFree
+			for _, h := range held {
+				if h == port {
+					status = StatusInUse
+				}
+			}
+			out = append(out, PortScanResult{Port: port, Status: status})
+		}
+		return out, nil
+	}
+}
+
+func TestFindFreePortsSkipsHeldExcludedAndUnbindable(t *testing.T) {
+	got, err := FindFreePorts(FreePortOptions{
+		Near:    3000,
+		Range:   PortRange{Min: 2995, Max: 3010},
+		Count:   3,
+		Exclude: []PortRange{{Min: 3002, Max: 3002}},
+		Scan:    fakeFreeScan(3000, 2999),
+		Bind: func(port int) error {
+			if port == 3001 {
+				return errors.New("address already in use")
+			}
+			return nil
+		},
+		SystemRanges: func() []PortRange { return []PortRange{{Min: 3003, Max: 3004}} },
+	})
+	if err != nil {
+		t.Fatalf("FindFreePorts: %v", err)
+	}
+	if want := []int{2998, 2997, 2996}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestFindFreePortsReportsShortfall(t *testing.T) {
+	got, err := FindFreePorts(FreePortOptions{
+		Range:        PortRange{Min: 4000, Max: 4002},
+		Count:        3,
+		Scan:         fakeFreeScan(4001),
+		Bind:         func(int) error { return nil },
+		SystemRanges: func() []PortRange { return nil },
+	})
+	if !errors.Is(err, ErrNoFreePort) || !reflect.DeepEqual(got, []int{4000, 4002}) {
+		t.Fatalf("expected two ports and ErrNoFreePort, got %v, %v", got, err)
+	}
+	if _, err := FindFreePorts(FreePortOptions{Near: 80, Range: PortRange{Min: 3000, Max: 3100}}); err == nil {
+		t.Fatal("expected a start outside the range to be rejected")
+	}
+}
+
+func TestParsePortRange(t *testing.T) {
+	if r, err := ParsePortRange("3000-3100"); err != nil || r != (PortRange{Min: 3000, Max: 3100}) {
+		t.Fatalf("unexpected range %+v, %v", r, err)
+	}
+	if r, err := ParsePortRange("8080"); err != nil || r.String() != "8080" {
+		t.Fatalf("unexpected range %+v, %v", r, err)
+	}
+	for _, bad := range []string{"", "3100-3000", "0-10", "a-b", "1-70000"} {
+		if _, err := ParsePortRange(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestBindTest(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+	if err := BindTest(ln.Addr().(*net.TCPAddr).Port); err == nil {
+		t.Fatal("expected a held port to fail the bind test")
+	}
+}
@@ -0,0 +1,159 @@
+package ports
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"port_sentinel/internal/util"
+)
+
+type HealthStatus string
+
+const (
+	HealthHealthy   HealthStatus = "HEALTHY"
+	HealthUnhealthy HealthStatus = "UNHEALTHY"
+)
+
+const (
+	ProbeTCP     = "tcp"
+	ProbeHTTP    = "http"
+	ProbeHTTPS   = "https"
+	ProbeCommand = "command"
+)
+
+// HealthProbe describes how to check a watched port. For http/https, Path is
+// either a path on the port ("/healthz") or a full URL; ExpectStatus 0 accepts
+// any 2xx/3xx. Command arguments may use {port} as a placeholder.
+type HealthProbe struct {
+	Type         string   `json:"type"`
+	Path         string   `json:"path"`
+	ExpectStatus int      `json:"expectStatus"`
+	ExpectBody   string   `json:"expectBody"`
+	Command      []string `json:"command"`
+	TimeoutMs    int      `json:"timeoutMs"`
+}
+
+type HealthResult struct {
+	Status    HealthStatus `json:"status"`
+	LatencyMs int64        `json:"latencyMs"`
+	Detail    string       `json:"detail"`
+}
+
+func (p HealthProbe) Timeout() time.Duration {
+	if p.TimeoutMs <= 0 {
+		return 2 * time.Second
+	}
+	return time.Duration(p.TimeoutMs) * time.Millisecond
+}
+
+func (p HealthProbe) Validate() error {
+	switch p.Type {
+	case ProbeTCP, ProbeHTTP, ProbeHTTPS:
+		return nil
+	case ProbeCommand:
+		if len(p.Command) == 0 || strings.TrimSpace(p.Command[0]) == "" {
+			return errors.New("command probe requires a command")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown probe type %q", p.Type)
+}
+
+// RunHealthProbe executes probe against addr and reports health with latency.
+func RunHealthProbe(addr string, port int, probe HealthProbe) HealthResult {
+	start := time.Now()
+	detail, err := runProbe(addr, port, probe)
+	res := HealthResult{
+		Status:    HealthHealthy,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Detail:    detail,
+	}
+	if err != nil {
+		res.Status = HealthUnhealthy
+		res.Detail = err.Error()
+	}
+	return res
+}
+
+func runProbe(addr string, port int, probe HealthProbe) (string, error) {
+	timeout := probe.Timeout()
+	switch probe.Type {
+	case ProbeTCP:
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return "", err
+		}
+		conn.Close()
+		return "connected", nil
+	case ProbeHTTP, ProbeHTTPS:
+		return probeHTTPHealth(addr, probe, timeout)
+	case ProbeCommand:
+		args := make([]string, 0, len(probe.Command))
+		for _, arg := range probe.Command {
+			args = append(args, strings.ReplaceAll(arg, "{port}", strconv.Itoa(port)))
+		}
+		res := util.RunCommand(timeout, args[0], args[1:]...)
+		out := firstLine(util.CleanOutput(res.Stdout + "\n" + res.Stderr))
+		if res.Err != nil {
+			if out != "" {
+				return "", fmt.Errorf("%v: %s", res.Err, out)
+			}
+			return "", res.Err
+		}
+		return out, nil
+	}
+	return "", fmt.Errorf("unknown probe type %q", probe.Type)
+}
+
+func probeHTTPHealth(addr string, probe HealthProbe, timeout time.Duration) (string, error) {
+	url := probe.Path
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		if !strings.HasPrefix(url, "/") {
+			url = "/" + url
+		}
+		url = probe.Type + "://" + addr + url
+	}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// Dev certificates are inspected separately; health only cares
+			// whether the service answers.
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	defer client.CloseIdleConnections()
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if probe.ExpectStatus != 0 {
+		if resp.StatusCode != probe.ExpectStatus {
+			return "", fmt.Errorf("status %d, expected %d", resp.StatusCode, probe.ExpectStatus)
+		}
+	} else if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if probe.ExpectBody != "" {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if !strings.Contains(string(body), probe.ExpectBody) {
+			return "", fmt.Errorf("status %d, body missing %q", resp.StatusCode, probe.ExpectBody)
+		}
+	}
+	return fmt.Sprintf("status %d", resp.StatusCode), nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
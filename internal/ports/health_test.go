@@ -0,0 +1,64 @@
+package ports
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunHealthProbeHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	res := RunHealthProbe(addr, 0, HealthProbe{Type: ProbeHTTP, Path: "/healthz", ExpectBody: `"ok"`})
+	if res.Status != HealthHealthy {
+		t.Fatalf("expected healthy, got %+v", res)
+	}
+
+	res = RunHealthProbe(addr, 0, HealthProbe{Type: ProbeHTTP, Path: "/broken"})
+	if res.Status != HealthUnhealthy || !strings.Contains(res.Detail, "500") {
+		t.Fatalf("expected unhealthy 500, got %+v", res)
+	}
+
+	res = RunHealthProbe(addr, 0, HealthProbe{Type: ProbeHTTP, Path: "/", ExpectBody: "ready"})
+	if res.Status != HealthUnhealthy {
+		t.Fatalf("expected body mismatch to be unhealthy, got %+v", res)
+	}
+}
+
+func TestRunHealthProbeTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+
+	if res := RunHealthProbe(addr, 0, HealthProbe{Type: ProbeTCP, TimeoutMs: 500}); res.Status != HealthHealthy {
+		t.Fatalf("expected healthy tcp probe, got %+v", res)
+	}
+	ln.Close()
+	if res := RunHealthProbe(addr, 0, HealthProbe{Type: ProbeTCP, TimeoutMs: 500}); res.Status != HealthUnhealthy {
+		t.Fatalf("expected unhealthy tcp probe after close, got %+v", res)
+	}
+}
+
+func TestHealthProbeValidate(t *testing.T) {
+	if err := (HealthProbe{Type: ProbeCommand}).Validate(); err == nil {
+		t.Fatalf("expected command probe without command to be rejected")
+	}
+	if err := (HealthProbe{Type: "ftp"}).Validate(); err == nil {
+		t.Fatalf("expected unknown probe type to be rejected")
+	}
+	if err := (HealthProbe{Type: ProbeHTTPS, Path: "/"}).Validate(); err != nil {
+		t.Fatalf("expected https probe to be valid, got %v", err)
+	}
+}
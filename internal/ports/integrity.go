@@ -0,0 +1,35 @@
+package ports
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// HashExecutable returns the SHA-256 of the binary a process is running. On
+// Linux it reads /proc/<pid>/exe, which still resolves after the file on disk
+// has been deleted or replaced.
+func HashExecutable(pid int, exePath string) (string, error) {
+	path := exePath
+	if runtime.GOOS == "linux" && pid > 0 {
+		path = filepath.Join("/proc", strconv.Itoa(pid), "exe")
+	}
+	if path == "" {
+		return "", errors.New("executable path unknown")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,186 @@
+package ports
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Endpoint is something a dev tool exposes about itself, e.g. a Node
+// inspector target or a Spring Boot actuator summary. URL is meant to be
+// copied by the user.
+type Endpoint struct {
+	Kind   string `json:"kind"`
+	Title  string `json:"title"`
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+	URL    string `json:"url"`
+}
+
+// Introspector queries one kind of dev endpoint. Introspect returns false when
+// the server at baseURL does not speak its protocol.
+type Introspector interface {
+	Name() string
+	Introspect(client *http.Client, baseURL string) ([]Endpoint, bool)
+}
+
+func DefaultIntrospectors() []Introspector {
+	return []Introspector{
+		nodeInspector{},
+		springActuator{},
+		goPprof{},
+	}
+}
+
+// Introspect asks each introspector in turn and returns the endpoints of the
+// first one that recognises the server at addr.
+func Introspect(addr string, timeout time.Duration, introspectors []Introspector) []Endpoint {
+	client := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	defer client.CloseIdleConnections()
+	baseURL := "http://" + addr
+	for _, in := range introspectors {
+		if endpoints, ok := in.Introspect(client, baseURL); ok {
+			return endpoints
+		}
+	}
+	return nil
+}
+
+func getBody(client *http.Client, url string) (*http.Response, []byte, bool) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, nil, false
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256*1024))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, nil, false
+	}
+	return resp, body, true
+}
+
+type nodeInspector struct{}
+
+func (nodeInspector) Name() string { return "node-inspector" }
+
+func (n nodeInspector) Introspect(client *http.Client, baseURL string) ([]Endpoint, bool) {
+	_, body, ok := getBody(client, baseURL+"/json/list")
+	if !ok {
+		return nil, false
+	}
+	var targets []struct {
+		Title                string `json:"title"`
+		Type                 string `json:"type"`
+		URL                  string `json:"url"`
+		DevtoolsFrontendURL  string `json:"devtoolsFrontendUrl"`
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}
+	if err := json.Unmarshal(body, &targets); err != nil {
+		return nil, false
+	}
+	out := make([]Endpoint, 0, len(targets))
+	for _, target := range targets {
+		if target.WebSocketDebuggerURL == "" {
+			continue
+		}
+		devtools := target.DevtoolsFrontendURL
+		if !strings.HasPrefix(devtools, "devtools://") {
+			devtools = "devtools://devtools/bundled/js_app.html?experiments=true&v8only=true&ws=" +
+				strings.TrimPrefix(target.WebSocketDebuggerURL, "ws://")
+		}
+		out = append(out, Endpoint{
+			Kind:   n.Name(),
+			Title:  firstNonBlank(target.URL, target.Title),
+			Type:   target.Type,
+			Detail: target.Title,
+			URL:    devtools,
+		})
+	}
+	return out, len(out) > 0
+}
+
+type springActuator struct{}
+
+func (springActuator) Name() string { return "spring-actuator" }
+
+func (a springActuator) Introspect(client *http.Client, baseURL string) ([]Endpoint, bool) {
+	resp, body, ok := getBody(client, baseURL+"/actuator/info")
+	if !ok {
+		return nil, false
+	}
+	var info map[string]json.RawMessage
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, false
+	}
+	_, hasBuild := info["build"]
+	_, hasApp := info["app"]
+	_, hasGit := info["git"]
+	if !strings.Contains(resp.Header.Get("Content-Type"), "actuator") && !hasBuild && !hasApp && !hasGit {
+		return nil, false
+	}
+	var meta struct {
+		Name     string `json:"name"`
+		Version  string `json:"version"`
+		Artifact string `json:"artifact"`
+	}
+	for _, key := range []string{"build", "app"} {
+		if raw, ok := info[key]; ok {
+			_ = json.Unmarshal(raw, &meta)
+			if meta.Name != "" || meta.Artifact != "" {
+				break
+			}
+		}
+	}
+	title := strings.TrimSpace(firstNonBlank(meta.Name, meta.Artifact, "Spring Boot application") + " " + meta.Version)
+	var git struct {
+		Branch string `json:"branch"`
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	detail := ""
+	if raw, ok := info["git"]; ok && json.Unmarshal(raw, &git) == nil && git.Branch != "" {
+		detail = "git " + git.Branch + " " + git.Commit.ID
+	}
+	return []Endpoint{{
+		Kind:   a.Name(),
+		Title:  title,
+		Type:   "actuator",
+		Detail: strings.TrimSpace(detail),
+		URL:    baseURL + "/actuator",
+	}}, true
+}
+
+type goPprof struct{}
+
+func (goPprof) Name() string { return "go-pprof" }
+
+func (p goPprof) Introspect(client *http.Client, baseURL string) ([]Endpoint, bool) {
+	_, body, ok := getBody(client, baseURL+"/debug/pprof/")
+	if !ok || !strings.Contains(string(body), "Types of profiles available") {
+		return nil, false
+	}
+	return []Endpoint{{
+		Kind:   p.Name(),
+		Title:  "Go pprof",
+		Type:   "pprof",
+		Detail: "go tool pprof " + baseURL + "/debug/pprof/profile",
+		URL:    baseURL + "/debug/pprof/",
+	}}, true
+}
+
+func firstNonBlank(values ...string) string {
+	for _, val := range values {
+		if strings.TrimSpace(val) != "" {
+			return val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package ports
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIntrospectNodeInspector(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/json/list" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{
+			"description": "node.js instance",
+			"devtoolsFrontendUrl": "devtools://devtools/bundled/js_app.html?experiments=true&v8only=true&ws=127.0.0.1:9229/abc",
+			"id": "abc",
+			"title": "server.js",
+			"type": "node",
+			"url": "file:///srv/app/server.js",
+			"webSocketDebuggerUrl": "ws://127.0.0.1:9229/abc"
+		}]`))
+	}))
+	defer srv.Close()
+
+	endpoints := Introspect(strings.TrimPrefix(srv.URL, "http://"), time.Second, DefaultIntrospectors())
+	if len(endpoints) != 1 {
+		t.Fatalf("expected one inspector target, got %+v", endpoints)
+	}
+	ep := endpoints[0]
+	if ep.Kind != "node-inspector" || ep.Type != "node" || ep.Title != "file:///srv/app/server.js" {
+		t.Fatalf("unexpected endpoint: %+v", ep)
+	}
+	if !strings.HasPrefix(ep.URL, "devtools://") {
+		t.Fatalf("expected devtools URL, got %q", ep.URL)
+	}
+}
+
+func TestIntrospectSpringActuatorAndPprof(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/actuator/info":
+			w.Header().Set("Content-Type", "application/vnd.spring-boot.actuator.v3+json")
+			w.Write([]byte(`{"build":{"name":"orders","version":"1.4.2"},"git":{"branch":"main","commit":{"id":"abc1234"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	endpoints := Introspect(strings.TrimPrefix(srv.URL, "http://"), time.Second, DefaultIntrospectors())
+	if len(endpoints) != 1 || endpoints[0].Title != "orders 1.4.2" || endpoints[0].Detail != "git main abc1234" {
+		t.Fatalf("unexpected actuator endpoints: %+v", endpoints)
+	}
+
+	pprof := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/debug/pprof/" {
+			w.Write([]byte("<html>Types of profiles available: ...</html>"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer pprof.Close()
+	endpoints = Introspect(strings.TrimPrefix(pprof.URL, "http://"), time.Second, DefaultIntrospectors())
+	if len(endpoints) != 1 || endpoints[0].Kind != "go-pprof" {
+		t.Fatalf("unexpected pprof endpoints: %+v", endpoints)
+	}
+}
+
+func TestIntrospectIgnoresPlainHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	if endpoints := Introspect(strings.TrimPrefix(srv.URL, "http://"), time.Second, DefaultIntrospectors()); len(endpoints) != 0 {
+		t.Fatalf("expected no endpoints for a plain HTTP server, got %+v", endpoints)
+	}
+}
@@ -0,0 +1,140 @@
+package ports
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"port_sentinel/internal/util"
+)
+
+// LaunchSpec is what is needed to start a process again. Env is nil when the
+// original environment could not be read; the new process then inherits ours.
+type LaunchSpec struct {
+	Argv []string `json:"argv"`
+	Dir  string   `json:"dir"`
+	Env  []string `json:"env,omitempty"`
+}
+
+// CaptureLaunchSpec records argv, working directory and environment of pid.
+// Linux reads them exactly from /proc; elsewhere argv is split from the
+// command line reported by ps/wmic and may lose quoting.
+func CaptureLaunchSpec(pid int) (LaunchSpec, error) {
+	if pid <= 0 {
+		return LaunchSpec{}, errors.New("invalid pid")
+	}
+	var spec LaunchSpec
+	if runtime.GOOS == "linux" {
+		dir := filepath.Join("/proc", strconv.Itoa(pid))
+		data, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+		if err != nil {
+			return LaunchSpec{}, err
+		}
+		spec.Argv = strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+	} else {
+		info, err := GetProcessInfo(pid)
+		if err != nil {
+			return LaunchSpec{}, err
+		}
+		spec.Argv = splitCommandLine(info.CommandLine)
+	}
+	if cwd, err := ProcessCwd(pid); err == nil {
+		spec.Dir = cwd
+	}
+	if len(spec.Argv) == 0 || spec.Argv[0] == "" {
+		return LaunchSpec{}, errors.New("command line is not readable")
+	}
+	if vars, err := ReadEnviron(pid); err == nil {
+		spec.Env = make([]string, 0, len(vars))
+		for _, v := range vars {
+			spec.Env = append(spec.Env, v.Key+"="+v.Value)
+		}
+	}
+	return spec, nil
+}
+
+// ProcessCwd returns the working directory of pid. Windows does not expose
+// it to other processes.
+func ProcessCwd(pid int) (string, error) {
+	if pid <= 0 {
+		return "", errors.New("invalid pid")
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), "cwd"))
+	case "darwin":
+		lsof := util.RunCommand(4*time.Second, "lsof", "-a", "-p", strconv.Itoa(pid), "-d", "cwd", "-Fn")
+		for _, line := range strings.Split(util.CleanOutput(lsof.Stdout), "\n") {
+			if cwd, ok := strings.CutPrefix(line, "n"); ok {
+				return cwd, nil
+			}
+		}
+		if lsof.Err != nil {
+			return "", lsof.Err
+		}
+		return "", errors.New("working directory is not readable")
+	default:
+		return "", errors.New("working directory is not available on " + runtime.GOOS)
+	}
+}
+
+// StartDetached launches spec in its own session/process group with no
+// stdio, so it survives Port Sentinel exiting. It returns the new PID.
+func StartDetached(spec LaunchSpec) (int, error) {
+	if len(spec.Argv) == 0 {
+		return 0, errors.New("empty command")
+	}
+	cmd := exec.Command(spec.Argv[0], spec.Argv[1:]...)
+	cmd.Dir = spec.Dir
+	cmd.Env = spec.Env
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	// Reap the child when it eventually exits instead of leaving a zombie.
+	go func() { _ = cmd.Wait() }()
+	return pid, nil
+}
+
+// splitCommandLine splits a command line on whitespace, honouring double
+// quotes and backslash-escaped quotes.
+func splitCommandLine(line string) []string {
+	var (
+		out     []string
+		current strings.Builder
+		quoted  bool
+		hasArg  bool
+	)
+	runes := []rune(strings.TrimSpace(line))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && runes[i+1] == '"':
+			current.WriteRune('"')
+			hasArg = true
+			i++
+		case r == '"':
+			quoted = !quoted
+			hasArg = true
+		case (r == ' ' || r == '\t') && !quoted:
+			if hasArg {
+				out = append(out, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		out = append(out, current.String())
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package ports
+
+import (
+	"os/exec"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	got := splitCommandLine(`"C:\Program Files\nodejs\node.exe" server.js --title "my \"app\"" ""`)
+	want := []string{`C:\Program Files\nodejs\node.exe`, "server.js", "--title", `my "app"`, ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestStartDetachedAndCapture(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("exact capture is Linux-only")
+	}
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not available")
+	}
+	dir := t.TempDir()
+	pid, err := StartDetached(LaunchSpec{Argv: []string{sleep, "5"}, Dir: dir, Env: []string{"PORT=4321"}})
+	if err != nil {
+		t.Fatalf("StartDetached failed: %v", err)
+	}
+	defer SignalPID(pid, SignalKILL)
+
+	spec, err := CaptureLaunchSpec(pid)
+	if err != nil {
+		t.Fatalf("CaptureLaunchSpec failed: %v", err)
+	}
+	if !reflect.DeepEqual(spec.Argv, []string{sleep, "5"}) || spec.Dir != dir {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+	if !reflect.DeepEqual(spec.Env, []string{"PORT=4321"}) {
+		t.Fatalf("expected environment to round-trip, got %v", spec.Env)
+	}
+}
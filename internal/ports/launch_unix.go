@@ -0,0 +1,9 @@
+//go:build darwin || linux
+
+package ports
+
+import "syscall"
+
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
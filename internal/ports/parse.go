@@ -5,8 +5,8 @@ import (
 	"strings"
 )
 
-func parseWindowsNetstat(output string) map[int]PortInfo {
-	out := map[int]PortInfo{}
+func parseWindowsNetstat(output string) []PortInfo {
+	out := make([]PortInfo, 0)
 	lines := strings.Split(output, "\n")
 	for _, raw := range lines {
 		line := strings.TrimSpace(raw)
@@ -29,16 +29,17 @@ func parseWindowsNetstat(output string) map[int]PortInfo {
 			continue
 		}
 		pid, _ := strconv.Atoi(fields[4])
-		out[port] = PortInfo{
+		out = append(out, PortInfo{
+			Port:         port,
 			PID:          pid,
 			LocalAddress: fields[1],
-		}
+		})
 	}
 	return out
 }
 
-func parseLsof(output string) map[int]PortInfo {
-	out := map[int]PortInfo{}
+func parseLsof(output string) []PortInfo {
+	out := make([]PortInfo, 0)
 	lines := strings.Split(output, "\n")
 	for _, raw := range lines {
 		line := strings.TrimSpace(raw)
@@ -61,10 +62,16 @@ func parseLsof(output string) map[int]PortInfo {
 		if port == 0 {
 			continue
 		}
-		out[port] = PortInfo{
+		user := ""
+		if len(fields) > 2 {
+			user = fields[2]
+		}
+		out = append(out, PortInfo{
+			Port:         port,
 			PID:          pid,
 			LocalAddress: addr,
-		}
+			User:         user,
+		})
 	}
 	return out
 }
@@ -89,8 +96,8 @@ func extractLsofAddress(line string) string {
 	return parts[len(parts)-1]
 }
 
-func parseUnixNetstat(output string) map[int]PortInfo {
-	out := map[int]PortInfo{}
+func parseUnixNetstat(output string) []PortInfo {
+	out := make([]PortInfo, 0)
 	lines := strings.Split(output, "\n")
 	for _, raw := range lines {
 		line := strings.TrimSpace(raw)
@@ -114,10 +121,11 @@ func parseUnixNetstat(output string) map[int]PortInfo {
 		if idx := strings.Index(last, "/"); idx > 0 {
 			pid, _ = strconv.Atoi(last[:idx])
 		}
-		out[port] = PortInfo{
+		out = append(out, PortInfo{
+			Port:         port,
 			PID:          pid,
 			LocalAddress: local,
-		}
+		})
 	}
 	return out
 }
@@ -146,3 +154,155 @@ func parsePortFromAddress(addr string) int {
 	port, _ := strconv.Atoi(strings.TrimSpace(addr))
 	return port
 }
+
+// parsePsTable parses `ps -axo pid=,ppid=,tty=,comm=` output. The command
+// may contain spaces; a tty of "?" (Linux) or "??" (macOS) means none.
+func parsePsTable(output string) []ProcessEntry {
+	out := make([]ProcessEntry, 0)
+	for _, raw := range strings.Split(output, "\n") {
+		fields := strings.Fields(raw)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		tty := fields[2]
+		if strings.Trim(tty, "?") == "" {
+			tty = ""
+		}
+		out = append(out, ProcessEntry{PID: pid, PPID: ppid, TTY: tty, Name: strings.Join(fields[3:], " ")})
+	}
+	return out
+}
+
+// parseWmicProcessCSV parses `wmic process get Name,ParentProcessId,ProcessId
+// /FORMAT:CSV`, whose columns are Node,Name,ParentProcessId,ProcessId.
+func parseWmicProcessCSV(output string) []ProcessEntry {
+	out := make([]ProcessEntry, 0)
+	for _, raw := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(raw), ",")
+		if len(fields) < 4 {
+			continue
+		}
+		n := len(fields)
+		pid, err := strconv.Atoi(fields[n-1])
+		if err != nil {
+			continue
+		}
+		ppid, err := strconv.Atoi(fields[n-2])
+		if err != nil {
+			continue
+		}
+		out = append(out, ProcessEntry{PID: pid, PPID: ppid, Name: strings.Join(fields[1:n-2], ",")})
+	}
+	return out
+}
+
+// countLsofEstablished counts ESTABLISHED lines of lsof output whose local
+// side is port, i.e. clients connected to a server on port.
+func countLsofEstablished(output string, port int) int {
+	count := 0
+	for _, raw := range strings.Split(output, "\n") {
+		if !strings.Contains(raw, "(ESTABLISHED)") {
+			continue
+		}
+		for _, field := range strings.Fields(raw) {
+			local, _, ok := strings.Cut(field, "->")
+			if ok && parsePortFromAddress(local) == port {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// countWindowsEstablished counts netstat -ano ESTABLISHED rows owned by pid
+// with local port port.
+func countWindowsEstablished(output string, port, pid int) int {
+	count := 0
+	for _, raw := range strings.Split(output, "\n") {
+		fields := strings.Fields(raw)
+		if len(fields) < 5 || !strings.EqualFold(fields[0], "TCP") || !strings.EqualFold(fields[3], "ESTABLISHED") {
+			continue
+		}
+		owner, _ := strconv.Atoi(fields[4])
+		if owner == pid && parsePortFromAddress(fields[1]) == port {
+			count++
+		}
+	}
+	return count
+}
+
+// parsePortPair reads two whitespace-separated ports, the format of Linux's
+// ip_local_port_range and of two-name sysctl -n output on macOS.
+func parsePortPair(output string) (PortRange, bool) {
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return PortRange{}, false
+	}
+	lo, err1 := strconv.Atoi(fields[0])
+	hi, err2 := strconv.Atoi(fields[1])
+	r := PortRange{Min: lo, Max: hi}
+	return r, err1 == nil && err2 == nil && r.Valid()
+}
+
+// parsePortRangeList reads Linux's ip_local_reserved_ports, e.g.
+// "8080,9000-9010".
+func parsePortRangeList(output string) []PortRange {
+	out := make([]PortRange, 0)
+	for _, item := range strings.Split(strings.TrimSpace(output), ",") {
+		if r, err := ParsePortRange(item); err == nil {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// parseNetshDynamicPort reads "netsh int ipv4 show dynamicport tcp".
+func parseNetshDynamicPort(output string) (PortRange, bool) {
+	start, count := 0, 0
+	for _, raw := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(raw, ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "start port":
+			start = n
+		case "number of ports":
+			count = n
+		}
+	}
+	r := PortRange{Min: start, Max: start + count - 1}
+	return r, count > 0 && r.Valid()
+}
+
+// parseNetshExcludedRanges reads "netsh int ipv4 show excludedportrange
+// protocol=tcp"; rows are "start end" with an optional "*" for
+// administered exclusions.
+func parseNetshExcludedRanges(output string) []PortRange {
+	out := make([]PortRange, 0)
+	for _, raw := range strings.Split(output, "\n") {
+		fields := strings.Fields(raw)
+		if len(fields) < 2 {
+			continue
+		}
+		lo, err1 := strconv.Atoi(fields[0])
+		hi, err2 := strconv.Atoi(fields[1])
+		if r := (PortRange{Min: lo, Max: hi}); err1 == nil && err2 == nil && r.Valid() {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -9,11 +9,8 @@ func TestParseWindowsNetstat(t *testing.T) {
   TCP    127.0.0.1:3001         0.0.0.0:0              ESTABLISHED     4242
 `
 	out := parseWindowsNetstat(sample)
-	if info, ok := out[3000]; !ok || info.PID != 4242 {
-		t.Fatalf("expected port 3000 pid 4242, got %+v", info)
-	}
-	if _, ok := out[3001]; ok {
-		t.Fatalf("expected port 3001 to be excluded")
+	if len(out) != 2 || out[1].Port != 3000 || out[1].PID != 4242 || out[1].LocalAddress != "127.0.0.1:3000" {
+		t.Fatalf("expected ports 135 and 3000 with pid 4242, got %+v", out)
 	}
 }
 
@@ -21,10 +18,14 @@ func TestParseLsof(t *testing.T) {
 	sample := `
 COMMAND   PID USER   FD   TYPE             DEVICE SIZE/OFF NODE NAME
 node     1234 user   23u  IPv6 0x1234      0t0  TCP *:3000 (LISTEN)
+node     1234 user   24u  IPv4 0x1235      0t0  TCP 127.0.0.1:3000 (LISTEN)
 `
 	out := parseLsof(sample)
-	if info, ok := out[3000]; !ok || info.PID != 1234 {
-		t.Fatalf("expected port 3000 pid 1234, got %+v", info)
+	if len(out) != 2 || out[0].Port != 3000 || out[0].PID != 1234 {
+		t.Fatalf("expected both port 3000 sockets with pid 1234, got %+v", out)
+	}
+	if out[0].User != "user" || out[1].LocalAddress != "127.0.0.1:3000" {
+		t.Fatalf("expected user and address to be parsed, got %+v", out)
 	}
 }
 
@@ -34,10 +35,117 @@ tcp        0      0 0.0.0.0:22      0.0.0.0:*     LISTEN      1000/sshd
 tcp6       0      0 :::8080         :::*          LISTEN      2000/java
 `
 	out := parseUnixNetstat(sample)
-	if info, ok := out[22]; !ok || info.PID != 1000 {
-		t.Fatalf("expected port 22 pid 1000, got %+v", info)
+	if len(out) != 2 || out[0].Port != 22 || out[0].PID != 1000 {
+		t.Fatalf("expected port 22 pid 1000, got %+v", out)
+	}
+	if out[1].Port != 8080 || out[1].PID != 2000 {
+		t.Fatalf("expected port 8080 pid 2000, got %+v", out[1])
+	}
+}
+
+func TestListenerResultsKeepsEverySocket(t *testing.T) {
+	infos := []PortInfo{
+		{Port: 8080, LocalAddress: "127.0.0.1:8080"},
+		{Port: 22, LocalAddress: "0.0.0.0:22"},
+		{Port: 8080, LocalAddress: "*:8080"},
+		{Port: 8080, LocalAddress: "127.0.0.1:8080"},
+	}
+	out := listenerResults(infos)
+	if len(out) != 3 {
+		t.Fatalf("expected one result per port and address, got %+v", out)
+	}
+	if out[0].Port != 22 || out[1].LocalAddress != "*:8080" || out[2].LocalAddress != "127.0.0.1:8080" {
+		t.Fatalf("unexpected order %+v", out)
+	}
+	watched := buildResults([]int{8080, 9000}, infos, nil)
+	if len(watched) != 2 || watched[0].LocalAddress != "127.0.0.1:8080" || watched[1].Status != StatusFree {
+		t.Fatalf("expected one result per watched port, got %+v", watched)
+	}
+}
+
+func TestParsePsTable(t *testing.T) {
+	sample := `
+    1     0 ??       launchd
+  512     1 ??       Google Chrome Helper
+  640   600 ttys001  node
+  abc     1 ??       broken
+`
+	out := parsePsTable(sample)
+	if len(out) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", out)
 	}
-	if info, ok := out[8080]; !ok || info.PID != 2000 {
-		t.Fatalf("expected port 8080 pid 2000, got %+v", info)
+	if out[1].PID != 512 || out[1].PPID != 1 || out[1].TTY != "" || out[1].Name != "Google Chrome Helper" {
+		t.Fatalf("unexpected entry %+v", out[1])
+	}
+	if out[2].TTY != "ttys001" {
+		t.Fatalf("expected a controlling terminal, got %+v", out[2])
+	}
+}
+
+func TestParseWmicProcessCSV(t *testing.T) {
+	sample := `
+Node,Name,ParentProcessId,ProcessId
+HOST,services.exe,600,708
+HOST,node.exe,708,4242
+`
+	out := parseWmicProcessCSV(sample)
+	if len(out) != 2 || out[1].PID != 4242 || out[1].PPID != 708 || out[1].Name != "node.exe" {
+		t.Fatalf("unexpected entries %+v", out)
+	}
+}
+
+func TestCountLsofEstablished(t *testing.T) {
+	sample := `
+COMMAND  PID USER   FD   TYPE DEVICE SIZE/OFF NODE NAME
+node    1234 user   24u  IPv4 0x1        0t0  TCP 127.0.0.1:3000->127.0.0.1:51234 (ESTABLISHED)
+node    1234 user   25u  IPv6 0x2        0t0  TCP [::1]:3000->[::1]:51240 (ESTABLISHED)
+node    1234 user   26u  IPv4 0x3        0t0  TCP 127.0.0.1:51300->127.0.0.1:5432 (ESTABLISHED)
+`
+	if got := countLsofEstablished(sample, 3000); got != 2 {
+		t.Fatalf("expected 2 connections, got %d", got)
+	}
+}
+
+func TestCountWindowsEstablished(t *testing.T) {
+	sample := `
+  TCP    127.0.0.1:3000         0.0.0.0:0              LISTENING       4242
+  TCP    127.0.0.1:3000         127.0.0.1:51234        ESTABLISHED     4242
+  TCP    127.0.0.1:51234        127.0.0.1:3000         ESTABLISHED     9000
+`
+	if got := countWindowsEstablished(sample, 3000, 4242); got != 1 {
+		t.Fatalf("expected 1 connection, got %d", got)
+	}
+}
+
+func TestParsePortRangeOutputs(t *testing.T) {
+	if r, ok := parsePortPair("32768\t60999\n"); !ok || r != (PortRange{Min: 32768, Max: 60999}) {
+		t.Fatalf("unexpected ip_local_port_range %+v", r)
+	}
+	if got := parsePortRangeList("8080,9000-9010\n"); len(got) != 2 || got[1] != (PortRange{Min: 9000, Max: 9010}) {
+		t.Fatalf("unexpected reserved ports %+v", got)
+	}
+
+	dynamic := `
+Protocol tcp Dynamic Port Range
+---------------------------------
+Start Port      : 49152
+Number of Ports : 16384
+`
+	if r, ok := parseNetshDynamicPort(dynamic); !ok || r != DefaultEphemeralRange {
+		t.Fatalf("unexpected dynamic port range %+v", r)
+	}
+
+	excluded := `
+Protocol tcp Port Exclusion Ranges
+
+Start Port    End Port
+----------    --------
+      5357        5357
+     50000       50059     *
+
+* - Administered port exclusions.
+`
+	if got := parseNetshExcludedRanges(excluded); len(got) != 2 || got[1] != (PortRange{Min: 50000, Max: 50059}) {
+		t.Fatalf("unexpected excluded ranges %+v", got)
 	}
 }
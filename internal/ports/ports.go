@@ -22,16 +22,43 @@ const (
 )
 
 type PortScanResult struct {
-	Port         int        `json:"port"`
-	Status       PortStatus `json:"status"`
-	Protocol     Protocol   `json:"protocol"`
-	PID          int        `json:"pid"`
-	ProcessName  string     `json:"processName"`
-	CommandLine  string     `json:"commandLine"`
-	ExePath      string     `json:"exePath"`
-	LocalAddress string     `json:"localAddress"`
-	Error        string     `json:"error"`
-	UpdatedAt    time.Time  `json:"updatedAt"`
+	Port           int                 `json:"port"`
+	Status         PortStatus          `json:"status"`
+	Protocol       Protocol            `json:"protocol"`
+	PID            int                 `json:"pid"`
+	ProcessName    string              `json:"processName"`
+	CommandLine    string              `json:"commandLine"`
+	ExePath        string              `json:"exePath"`
+	ExeDeleted     bool                `json:"exeDeleted"`
+	ExeSHA256      string              `json:"exeSha256,omitempty"`
+	StartTime      time.Time           `json:"startTime,omitempty"`
+	User           string              `json:"user"`
+	LocalAddress   string              `json:"localAddress"`
+	Forward        *ForwardInfo        `json:"forward,omitempty"`
+	Classification *Classification     `json:"classification,omitempty"`
+	Service        *ServiceFingerprint `json:"service,omitempty"`
+	Health         *HealthResult       `json:"health,omitempty"`
+	TLS            *CertInfo           `json:"tls,omitempty"`
+	Endpoints      []Endpoint          `json:"endpoints,omitempty"`
+	Resources      *ResourceUsage      `json:"resources,omitempty"`
+	Orphan         *OrphanInfo         `json:"orphan,omitempty"`
+	Warnings       []string            `json:"warnings,omitempty"`
+	Error          string              `json:"error"`
+	UpdatedAt      time.Time           `json:"updatedAt"`
+}
+
+type Classification struct {
+	Label    string `json:"label"`
+	Category string `json:"category"`
+	Icon     string `json:"icon"`
+	Color    string `json:"color"`
+}
+
+func (r PortScanResult) DisplayName() string {
+	if r.Classification != nil && r.Classification.Label != "" {
+		return r.Classification.Label
+	}
+	return r.ProcessName
 }
 
 type ProcessInfo struct {
@@ -39,11 +66,164 @@ type ProcessInfo struct {
 	ProcessName string
 	CommandLine string
 	ExePath     string
+	ExeDeleted  bool
+	User        string
+	StartTime   time.Time
 }
 
+// PortInfo is one listening socket as reported by lsof or netstat.
 type PortInfo struct {
+	Port         int
 	PID          int
 	LocalAddress string
+	User         string
+}
+
+// listenerKey identifies a socket: one port can be bound on several
+// addresses and, with SO_REUSEPORT or forked workers, by several processes.
+type listenerKey struct {
+	port    int
+	address string
+	pid     int
+}
+
+// buildResults turns raw listener info into one result per port in portList,
+// resolving each owning process once. When several sockets share a port the
+// first one reported is shown.
+func buildResults(portList []int, infos []PortInfo, scanErr error) []PortScanResult {
+	byPort := make(map[int]PortInfo, len(infos))
+	for _, info := range infos {
+		if _, ok := byPort[info.Port]; !ok {
+			byPort[info.Port] = info
+		}
+	}
+	resolver := newProcessResolver()
+	results := make([]PortScanResult, 0, len(portList))
+	for _, port := range portList {
+		res := PortScanResult{
+			Port:      port,
+			Status:    StatusFree,
+			Protocol:  ProtocolTCP,
+			UpdatedAt: NowStamp(),
+		}
+		if info, ok := byPort[port]; ok {
+			resolver.apply(&res, info)
+		} else if scanErr != nil {
+			res.Status = StatusUnknown
+			res.Error = scanErr.Error()
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// listenerResults returns one result per socket, ordered by port, address
+// and PID. Lines repeated by lsof for the same socket are reported once.
+func listenerResults(infos []PortInfo) []PortScanResult {
+	seen := make(map[listenerKey]bool, len(infos))
+	unique := make([]PortInfo, 0, len(infos))
+	for _, info := range infos {
+		key := listenerKey{port: info.Port, address: info.LocalAddress, pid: info.PID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, info)
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		a, b := unique[i], unique[j]
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		if a.LocalAddress != b.LocalAddress {
+			return a.LocalAddress < b.LocalAddress
+		}
+		return a.PID < b.PID
+	})
+	resolver := newProcessResolver()
+	results := make([]PortScanResult, 0, len(unique))
+	for _, info := range unique {
+		res := PortScanResult{
+			Port:      info.Port,
+			Status:    StatusFree,
+			Protocol:  ProtocolTCP,
+			UpdatedAt: NowStamp(),
+		}
+		resolver.apply(&res, info)
+		results = append(results, res)
+	}
+	return results
+}
+
+// processResolver looks up each owning process once per scan.
+type processResolver struct {
+	infos  map[int]ProcessInfo
+	errors map[int]string
+}
+
+func newProcessResolver() *processResolver {
+	return &processResolver{infos: map[int]ProcessInfo{}, errors: map[int]string{}}
+}
+
+// apply marks res in use by the socket in info and fills in its process.
+func (r *processResolver) apply(res *PortScanResult, info PortInfo) {
+	res.Status = StatusInUse
+	res.PID = info.PID
+	res.LocalAddress = info.LocalAddress
+	res.User = info.User
+	if info.PID > 0 {
+		if pinfo, ok := r.infos[info.PID]; ok {
+			applyProcessInfo(res, pinfo)
+		} else if errMsg, ok := r.errors[info.PID]; ok {
+			res.Error = errMsg
+		} else if pinfo, err := GetProcessInfo(info.PID); err == nil {
+			r.infos[info.PID] = pinfo
+			applyProcessInfo(res, pinfo)
+		} else {
+			r.errors[info.PID] = err.Error()
+			res.Error = err.Error()
+		}
+	}
+	enrichResult(res)
+}
+
+func applyProcessInfo(res *PortScanResult, info ProcessInfo) {
+	res.ProcessName = info.ProcessName
+	res.CommandLine = info.CommandLine
+	res.ExePath = info.ExePath
+	res.ExeDeleted = info.ExeDeleted
+	res.StartTime = info.StartTime
+	if info.User != "" {
+		res.User = info.User
+	}
+}
+
+func enrichResult(res *PortScanResult) {
+	if res.Status != StatusInUse {
+		return
+	}
+	if res.ExeDeleted {
+		res.Warnings = append(res.Warnings, "executable was deleted or replaced on disk; the process is still running the old binary")
+	}
+	if fwd, ok := DescribeForward(res.ProcessName, res.CommandLine, res.Port); ok {
+		res.Forward = &fwd
+	}
+}
+
+// SameProcess reports whether info still describes the process captured in
+// res, guarding against the PID having been reused. Unknown fields are not
+// compared.
+func SameProcess(res PortScanResult, info ProcessInfo) bool {
+	if !res.StartTime.IsZero() && !info.StartTime.IsZero() {
+		delta := res.StartTime.Sub(info.StartTime)
+		if delta > time.Second || delta < -time.Second {
+			return false
+		}
+	}
+	if res.ExePath != "" && info.ExePath != "" && res.ExePath != info.ExePath {
+		return false
+	}
+	return true
 }
 
 func DefaultPresetPorts() map[int]bool {
@@ -27,48 +27,21 @@ func ScanPort(port int) (PortScanResult, error) {
 }
 
 func ScanPorts(ports []int) ([]PortScanResult, error) {
-	results := make([]PortScanResult, 0, len(ports))
-	infoMap, scanErr := scanListeningPorts()
-	procInfoCache := map[int]ProcessInfo{}
-	procErrCache := map[int]string{}
-	for _, port := range ports {
-		res := PortScanResult{
-			Port:      port,
-			Status:    StatusFree,
-			Protocol:  ProtocolTCP,
-			UpdatedAt: NowStamp(),
-		}
-		if info, ok := infoMap[port]; ok {
-			res.Status = StatusInUse
-			res.PID = info.PID
-			res.LocalAddress = info.LocalAddress
-			if info.PID > 0 {
-				if pinfo, ok := procInfoCache[info.PID]; ok {
-					res.ProcessName = pinfo.ProcessName
-					res.CommandLine = pinfo.CommandLine
-					res.ExePath = pinfo.ExePath
-				} else if errMsg, ok := procErrCache[info.PID]; ok {
-					res.Error = errMsg
-				} else if pinfo, err := GetProcessInfo(info.PID); err == nil {
-					procInfoCache[info.PID] = pinfo
-					res.ProcessName = pinfo.ProcessName
-					res.CommandLine = pinfo.CommandLine
-					res.ExePath = pinfo.ExePath
-				} else {
-					procErrCache[info.PID] = err.Error()
-					res.Error = err.Error()
-				}
-			}
-		} else if scanErr != nil {
-			res.Status = StatusUnknown
-			res.Error = scanErr.Error()
-		}
-		results = append(results, res)
+	infos, scanErr := scanListeningPorts()
+	return buildResults(ports, infos, scanErr), scanErr
+}
+
+// ScanAllListeners reports every listening TCP socket on the machine, not just
+// the watched ports, with one result per port, address and PID.
+func ScanAllListeners() ([]PortScanResult, error) {
+	infos, err := scanListeningPorts()
+	if err != nil {
+		return nil, err
 	}
-	return results, scanErr
+	return listenerResults(infos), nil
 }
 
-func scanListeningPorts() (map[int]PortInfo, error) {
+func scanListeningPorts() ([]PortInfo, error) {
 	lsof := util.RunCommand(5*time.Second, "lsof", "-nP", "-iTCP", "-sTCP:LISTEN")
 	if lsof.Err == nil {
 		return parseLsof(util.CleanOutput(lsof.Stdout)), nil
@@ -79,7 +52,7 @@ func scanListeningPorts() (map[int]PortInfo, error) {
 		return parseUnixNetstat(util.CleanOutput(netstat.Stdout)), nil
 	}
 
-	return map[int]PortInfo{}, fmt.Errorf("lsof error: %v; netstat error: %v", lsof.Err, netstat.Err)
+	return nil, fmt.Errorf("lsof error: %v; netstat error: %v", lsof.Err, netstat.Err)
 }
 
 func GetProcessInfo(pid int) (ProcessInfo, error) {
@@ -96,9 +69,29 @@ func GetProcessInfo(pid int) (ProcessInfo, error) {
 		}
 		exePath := filepath.Join("/proc", strconv.Itoa(pid), "exe")
 		if path, err := os.Readlink(exePath); err == nil {
+			// The kernel appends " (deleted)" once the binary on disk has been
+			// removed or replaced, e.g. after a rebuild or package upgrade.
+			if trimmed, ok := strings.CutSuffix(path, " (deleted)"); ok {
+				path = trimmed
+				info.ExeDeleted = true
+			}
 			info.ExePath = path
 			info.ProcessName = filepath.Base(path)
 		}
+		stat, statErr := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+		procStat, procErr := os.ReadFile("/proc/stat")
+		if statErr == nil && procErr == nil {
+			if start, err := parseProcStartTime(string(stat), string(procStat)); err == nil {
+				info.StartTime = start
+			}
+		}
+	} else {
+		psStart := util.RunCommand(4*time.Second, "ps", "-p", strconv.Itoa(pid), "-o", "lstart=")
+		if psStart.Err == nil {
+			if start, err := time.ParseInLocation("Mon Jan _2 15:04:05 2006", strings.Join(strings.Fields(psStart.Stdout), " "), time.Local); err == nil {
+				info.StartTime = start
+			}
+		}
 	}
 
 	psComm := util.RunCommand(4*time.Second, "ps", "-p", strconv.Itoa(pid), "-o", "comm=")
@@ -111,20 +104,167 @@ func GetProcessInfo(pid int) (ProcessInfo, error) {
 		info.CommandLine = strings.TrimSpace(psCmd.Stdout)
 	}
 
+	psUser := util.RunCommand(4*time.Second, "ps", "-p", strconv.Itoa(pid), "-o", "user=")
+	if psUser.Err == nil {
+		info.User = strings.TrimSpace(psUser.Stdout)
+	}
+
 	return info, nil
 }
 
-func KillPID(pid int, force bool) error {
+// ReadProcessStat samples CPU time and RSS from /proc on Linux and ps
+// elsewhere.
+func ReadProcessStat(pid int) (ProcessStat, error) {
+	if pid <= 0 {
+		return ProcessStat{}, errors.New("invalid pid")
+	}
+	stat := ProcessStat{At: time.Now()}
+	if runtime.GOOS == "linux" {
+		dir := filepath.Join("/proc", strconv.Itoa(pid))
+		raw, err := os.ReadFile(filepath.Join(dir, "stat"))
+		if err != nil {
+			return ProcessStat{}, err
+		}
+		if stat.CPUTime, err = parseProcStat(string(raw)); err != nil {
+			return ProcessStat{}, err
+		}
+		raw, err = os.ReadFile(filepath.Join(dir, "statm"))
+		if err != nil {
+			return ProcessStat{}, err
+		}
+		if stat.RSSBytes, err = parseStatmRSS(string(raw), os.Getpagesize()); err != nil {
+			return ProcessStat{}, err
+		}
+		return stat, nil
+	}
+
+	ps := util.RunCommand(4*time.Second, "ps", "-p", strconv.Itoa(pid), "-o", "rss=,time=")
+	if ps.Err != nil {
+		return ProcessStat{}, ps.Err
+	}
+	fields := strings.Fields(util.CleanOutput(ps.Stdout))
+	if len(fields) < 2 {
+		return ProcessStat{}, fmt.Errorf("unexpected ps output %q", ps.Stdout)
+	}
+	kb, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return ProcessStat{}, err
+	}
+	stat.RSSBytes = kb * 1024
+	if stat.CPUTime, err = parsePsTime(fields[1]); err != nil {
+		return ProcessStat{}, err
+	}
+	return stat, nil
+}
+
+// ProcessExists reports whether pid is still running. Zombies count as
+// exited since they no longer hold any sockets.
+func ProcessExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	if runtime.GOOS == "linux" {
+		raw, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+		if err != nil {
+			return false
+		}
+		stat := string(raw)
+		if end := strings.LastIndexByte(stat, ')'); end >= 0 {
+			fields := strings.Fields(stat[end+1:])
+			return len(fields) == 0 || fields[0] != "Z"
+		}
+		return true
+	}
+	ps := util.RunCommand(4*time.Second, "ps", "-p", strconv.Itoa(pid), "-o", "stat=")
+	state := strings.TrimSpace(ps.Stdout)
+	return ps.Err == nil && state != "" && !strings.HasPrefix(state, "Z")
+}
+
+func SignalPID(pid int, sig Signal) error {
 	if pid <= 0 {
 		return errors.New("invalid pid")
 	}
-	signal := "-15"
-	if force {
-		signal = "-9"
+	if _, ok := ParseSignal(string(sig)); !ok {
+		return fmt.Errorf("unknown signal %q", sig)
 	}
-	res := util.RunCommand(5*time.Second, "kill", signal, strconv.Itoa(pid))
+	res := util.RunCommand(5*time.Second, "kill", "-"+string(sig), strconv.Itoa(pid))
 	if res.Err != nil {
-		return res.Err
+		return permissionError(res)
 	}
 	return nil
 }
+
+// SignalPIDElevated sends sig through an elevation helper, for processes
+// owned by other users.
+func SignalPIDElevated(pid int, sig Signal, method string) error {
+	if pid <= 0 {
+		return errors.New("invalid pid")
+	}
+	if _, ok := ParseSignal(string(sig)); !ok {
+		return fmt.Errorf("unknown signal %q", sig)
+	}
+	_, err := runElevated(method, "kill", "-"+string(sig), strconv.Itoa(pid))
+	return err
+}
+
+// ScanPortsElevated runs lsof through an elevation helper so sockets of
+// other users' processes are visible.
+func ScanPortsElevated(ports []int, method string) ([]PortScanResult, error) {
+	res, err := runElevated(method, "lsof", "-nP", "-iTCP", "-sTCP:LISTEN")
+	if err != nil {
+		return nil, err
+	}
+	return buildResults(ports, parseLsof(util.CleanOutput(res.Stdout)), nil), nil
+}
+
+func ListProcesses() ([]ProcessEntry, error) {
+	ps := util.RunCommand(5*time.Second, "ps", "-axo", "pid=,ppid=,tty=,comm=")
+	if ps.Err != nil {
+		return nil, ps.Err
+	}
+	return parsePsTable(util.CleanOutput(ps.Stdout)), nil
+}
+
+// CountConnections counts established client connections to port owned by
+// pid. lsof exits non-zero when nothing matches.
+func CountConnections(port, pid int) (int, error) {
+	if pid <= 0 {
+		return 0, errors.New("invalid pid")
+	}
+	lsof := util.RunCommand(5*time.Second, "lsof", "-nP", "-a", "-p", strconv.Itoa(pid), "-iTCP", "-sTCP:ESTABLISHED")
+	output := util.CleanOutput(lsof.Stdout)
+	if lsof.Err != nil && output != "" {
+		return 0, lsof.Err
+	}
+	return countLsofEstablished(output, port), nil
+}
+
+// EphemeralRange is the range the kernel assigns outgoing connections from.
+func EphemeralRange() PortRange {
+	if runtime.GOOS == "linux" {
+		if raw, err := os.ReadFile("/proc/sys/net/ipv4/ip_local_port_range"); err == nil {
+			if r, ok := parsePortPair(string(raw)); ok {
+				return r
+			}
+		}
+		return DefaultEphemeralRange
+	}
+	sysctl := util.RunCommand(2*time.Second, "sysctl", "-n", "net.inet.ip.portrange.first", "net.inet.ip.portrange.last")
+	if r, ok := parsePortPair(util.CleanOutput(sysctl.Stdout)); sysctl.Err == nil && ok {
+		return r
+	}
+	return DefaultEphemeralRange
+}
+
+// ReservedPortRanges lists ports the administrator keeps out of the
+// ephemeral range (Linux only).
+func ReservedPortRanges() []PortRange {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	raw, err := os.ReadFile("/proc/sys/net/ipv4/ip_local_reserved_ports")
+	if err != nil {
+		return nil
+	}
+	return parsePortRangeList(string(raw))
+}
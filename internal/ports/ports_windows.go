@@ -24,58 +24,26 @@ func ScanPort(port int) (PortScanResult, error) {
 }
 
 func ScanPorts(ports []int) ([]PortScanResult, error) {
-	results := make([]PortScanResult, 0, len(ports))
+	infos, scanErr := scanListeningPorts()
+	return buildResults(ports, infos, scanErr), scanErr
+}
+
+// ScanAllListeners reports every listening TCP socket on the machine, not just
+// the watched ports, with one result per port, address and PID.
+func ScanAllListeners() ([]PortScanResult, error) {
+	infos, err := scanListeningPorts()
+	if err != nil {
+		return nil, err
+	}
+	return listenerResults(infos), nil
+}
 
+func scanListeningPorts() ([]PortInfo, error) {
 	netstat := util.RunCommand(5*time.Second, "netstat", "-ano", "-p", "tcp")
 	if netstat.Err != nil {
-		for _, port := range ports {
-			results = append(results, PortScanResult{
-				Port:      port,
-				Status:    StatusUnknown,
-				Protocol:  ProtocolTCP,
-				Error:     netstat.Err.Error(),
-				UpdatedAt: NowStamp(),
-			})
-		}
-		return results, netstat.Err
-	}
-	infoMap := parseWindowsNetstat(util.CleanOutput(netstat.Stdout))
-	procInfoCache := map[int]ProcessInfo{}
-	procErrCache := map[int]string{}
-
-	for _, port := range ports {
-		res := PortScanResult{
-			Port:      port,
-			Status:    StatusFree,
-			Protocol:  ProtocolTCP,
-			UpdatedAt: NowStamp(),
-		}
-		if info, ok := infoMap[port]; ok {
-			res.Status = StatusInUse
-			res.PID = info.PID
-			res.LocalAddress = info.LocalAddress
-			if info.PID > 0 {
-				if pinfo, ok := procInfoCache[info.PID]; ok {
-					res.ProcessName = pinfo.ProcessName
-					res.CommandLine = pinfo.CommandLine
-					res.ExePath = pinfo.ExePath
-				} else if errMsg, ok := procErrCache[info.PID]; ok {
-					res.Error = errMsg
-				} else if pinfo, err := GetProcessInfo(info.PID); err == nil {
-					procInfoCache[info.PID] = pinfo
-					res.ProcessName = pinfo.ProcessName
-					res.CommandLine = pinfo.CommandLine
-					res.ExePath = pinfo.ExePath
-				} else {
-					procErrCache[info.PID] = err.Error()
-					res.Error = err.Error()
-				}
-			}
-		}
-		results = append(results, res)
+		return nil, netstat.Err
 	}
-
-	return results, nil
+	return parseWindowsNetstat(util.CleanOutput(netstat.Stdout)), nil
 }
 
 func GetProcessInfo(pid int) (ProcessInfo, error) {
@@ -84,7 +52,7 @@ func GetProcessInfo(pid int) (ProcessInfo, error) {
 	}
 	info := ProcessInfo{PID: pid}
 
-	tasklist := util.RunCommand(5*time.Second, "tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH")
+	tasklist := util.RunCommand(5*time.Second, "tasklist", "/V", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH")
 	if tasklist.Err != nil {
 		return info, tasklist.Err
 	}
@@ -94,9 +62,13 @@ func GetProcessInfo(pid int) (ProcessInfo, error) {
 		if len(parts) > 0 {
 			info.ProcessName = parts[0]
 		}
+		// Verbose columns: image, PID, session, session#, mem, status, user, ...
+		if len(parts) > 6 && parts[6] != "N/A" {
+			info.User = parts[6]
+		}
 	}
 
-	wmic := util.RunCommand(6*time.Second, "wmic", "process", "where", fmt.Sprintf("processid=%d", pid), "get", "CommandLine,ExecutablePath", "/FORMAT:LIST")
+	wmic := util.RunCommand(6*time.Second, "wmic", "process", "where", fmt.Sprintf("processid=%d", pid), "get", "CommandLine,CreationDate,ExecutablePath", "/FORMAT:LIST")
 	if wmic.Err == nil {
 		for _, raw := range strings.Split(util.CleanOutput(wmic.Stdout), "\n") {
 			line := strings.TrimSpace(raw)
@@ -106,27 +78,113 @@ func GetProcessInfo(pid int) (ProcessInfo, error) {
 			if strings.HasPrefix(line, "ExecutablePath=") {
 				info.ExePath = strings.TrimPrefix(line, "ExecutablePath=")
 			}
+			// CreationDate is a CIM datetime, e.g. 20261019101500.123456+120.
+			if val, ok := strings.CutPrefix(line, "CreationDate="); ok && len(val) >= 14 {
+				if start, err := time.ParseInLocation("20060102150405", val[:14], time.Local); err == nil {
+					info.StartTime = start
+				}
+			}
 		}
 	}
 
 	return info, nil
 }
 
-func KillPID(pid int, force bool) error {
+// ReadProcessStat samples CPU time and working set via wmic. Kernel and user
+// times are reported in 100ns units.
+func ReadProcessStat(pid int) (ProcessStat, error) {
 	if pid <= 0 {
-		return errors.New("invalid pid")
+		return ProcessStat{}, errors.New("invalid pid")
 	}
-	args := []string{"/PID", strconv.Itoa(pid), "/T"}
-	if force {
-		args = append(args, "/F")
+	stat := ProcessStat{At: time.Now()}
+	wmic := util.RunCommand(6*time.Second, "wmic", "process", "where", fmt.Sprintf("processid=%d", pid), "get", "KernelModeTime,UserModeTime,WorkingSetSize", "/FORMAT:LIST")
+	if wmic.Err != nil {
+		return ProcessStat{}, wmic.Err
+	}
+	found := false
+	for _, raw := range strings.Split(util.CleanOutput(wmic.Stdout), "\n") {
+		key, val, ok := strings.Cut(strings.TrimSpace(raw), "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(val), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "KernelModeTime", "UserModeTime":
+			stat.CPUTime += time.Duration(n) * 100 * time.Nanosecond
+		case "WorkingSetSize":
+			stat.RSSBytes = n
+			found = true
+		}
+	}
+	if !found {
+		return ProcessStat{}, fmt.Errorf("process %d not found", pid)
+	}
+	return stat, nil
+}
+
+func ProcessExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	tasklist := util.RunCommand(5*time.Second, "tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH")
+	if tasklist.Err != nil {
+		return false
+	}
+	return strings.Contains(tasklist.Stdout, fmt.Sprintf("\"%d\"", pid))
+}
+
+// SignalPID maps POSIX signals onto taskkill: KILL forces termination,
+// TERM/INT ask the process to close (there is no way to deliver Ctrl-C to
+// another console) and HUP and QUIT have no equivalent; closing the process
+// instead of reloading it would be a surprise.
+func SignalPID(pid int, sig Signal) error {
+	args, err := taskkillArgs(pid, sig)
+	if err != nil {
+		return err
 	}
 	res := util.RunCommand(8*time.Second, "taskkill", args...)
 	if res.Err != nil {
-		return res.Err
+		return permissionError(res)
 	}
 	return nil
 }
 
+// SignalPIDElevated runs taskkill through a UAC prompt.
+func SignalPIDElevated(pid int, sig Signal, method string) error {
+	args, err := taskkillArgs(pid, sig)
+	if err != nil {
+		return err
+	}
+	_, err = runElevated(method, append([]string{"taskkill"}, args...)...)
+	return err
+}
+
+// ScanPortsElevated is ScanPorts: netstat -ano already reports the owning
+// PID of every socket without administrator rights.
+func ScanPortsElevated(ports []int, _ string) ([]PortScanResult, error) {
+	return ScanPorts(ports)
+}
+
+func taskkillArgs(pid int, sig Signal) ([]string, error) {
+	if pid <= 0 {
+		return nil, errors.New("invalid pid")
+	}
+	args := []string{"/PID", strconv.Itoa(pid), "/T"}
+	switch sig {
+	case SignalKILL:
+		args = append(args, "/F")
+	case SignalTERM, SignalINT:
+	case SignalHUP, SignalQUIT:
+		return nil, ErrSignalUnsupported
+	default:
+		return nil, fmt.Errorf("unknown signal %q", sig)
+	}
+	return args, nil
+}
+
 func splitCSVLine(line string) []string {
 	line = strings.TrimSpace(line)
 	if line == "" {
@@ -136,3 +194,43 @@ func splitCSVLine(line string) []string {
 	line = strings.TrimSuffix(line, "\"")
 	return strings.Split(line, "\",\"")
 }
+
+func ListProcesses() ([]ProcessEntry, error) {
+	wmic := util.RunCommand(8*time.Second, "wmic", "process", "get", "Name,ParentProcessId,ProcessId", "/FORMAT:CSV")
+	if wmic.Err != nil {
+		return nil, wmic.Err
+	}
+	return parseWmicProcessCSV(util.CleanOutput(wmic.Stdout)), nil
+}
+
+// CountConnections counts established client connections to port owned by
+// pid.
+func CountConnections(port, pid int) (int, error) {
+	if pid <= 0 {
+		return 0, errors.New("invalid pid")
+	}
+	netstat := util.RunCommand(5*time.Second, "netstat", "-ano", "-p", "tcp")
+	if netstat.Err != nil {
+		return 0, netstat.Err
+	}
+	return countWindowsEstablished(util.CleanOutput(netstat.Stdout), port, pid), nil
+}
+
+// EphemeralRange is the range Windows assigns outgoing connections from.
+func EphemeralRange() PortRange {
+	netsh := util.RunCommand(3*time.Second, "netsh", "int", "ipv4", "show", "dynamicport", "tcp")
+	if r, ok := parseNetshDynamicPort(util.CleanOutput(netsh.Stdout)); netsh.Err == nil && ok {
+		return r
+	}
+	return DefaultEphemeralRange
+}
+
+// ReservedPortRanges lists the excluded port ranges, which Hyper-V, WSL and
+// Docker reserve and which cannot be bound even though nothing listens there.
+func ReservedPortRanges() []PortRange {
+	netsh := util.RunCommand(3*time.Second, "netsh", "int", "ipv4", "show", "excludedportrange", "protocol=tcp")
+	if netsh.Err != nil {
+		return nil
+	}
+	return parseNetshExcludedRanges(util.CleanOutput(netsh.Stdout))
+}
@@ -0,0 +1,124 @@
+package ports
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProcessStat is a point-in-time reading of a process's cumulative CPU time
+// and resident memory. CPU percentages are derived from two readings.
+type ProcessStat struct {
+	CPUTime  time.Duration
+	RSSBytes uint64
+	At       time.Time
+}
+
+// ResourceUsage is what the UI shows for a port's process: the latest
+// values plus a short history, oldest first.
+type ResourceUsage struct {
+	CPUPercent float64   `json:"cpuPercent"`
+	RSSBytes   uint64    `json:"rssBytes"`
+	CPUHistory []float64 `json:"cpuHistory,omitempty"`
+	RSSHistory []uint64  `json:"rssHistory,omitempty"`
+	OverLimit  bool      `json:"overLimit"`
+}
+
+// clockTicks is USER_HZ, which is 100 on every mainstream Linux architecture.
+const clockTicks = 100
+
+// parseProcStat extracts utime+stime from /proc/<pid>/stat. The command name
+// is in parentheses and may itself contain spaces or parentheses.
+func parseProcStat(stat string) (time.Duration, error) {
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, errors.New("malformed stat")
+	}
+	fields := strings.Fields(stat[end+1:])
+	// fields[0] is state (field 3); utime and stime are fields 14 and 15.
+	if len(fields) < 13 {
+		return 0, errors.New("malformed stat")
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(utime+stime) * time.Second / clockTicks, nil
+}
+
+// parseProcStartTime converts starttime (field 22 of /proc/<pid>/stat, in
+// ticks since boot) to wall-clock time using btime from /proc/stat.
+func parseProcStartTime(stat, procStat string) (time.Time, error) {
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return time.Time{}, errors.New("malformed stat")
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 20 {
+		return time.Time{}, errors.New("malformed stat")
+	}
+	ticks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, line := range strings.Split(procStat, "\n") {
+		if val, ok := strings.CutPrefix(line, "btime "); ok {
+			boot, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(boot, 0).Add(time.Duration(ticks) * time.Second / clockTicks), nil
+		}
+	}
+	return time.Time{}, errors.New("btime not found")
+}
+
+// parseStatmRSS returns resident pages from /proc/<pid>/statm.
+func parseStatmRSS(statm string, pageSize int) (uint64, error) {
+	fields := strings.Fields(statm)
+	if len(fields) < 2 {
+		return 0, errors.New("malformed statm")
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return pages * uint64(pageSize), nil
+}
+
+// parsePsTime parses the cputime column of ps: [[dd-]hh:]mm:ss[.ss].
+func parsePsTime(val string) (time.Duration, error) {
+	val = strings.TrimSpace(val)
+	var days int
+	if d, rest, ok := strings.Cut(val, "-"); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, err
+		}
+		days, val = n, rest
+	}
+	parts := strings.Split(val, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, errors.New("malformed cputime")
+	}
+	secs, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, err
+	}
+	total := time.Duration(secs * float64(time.Second))
+	unit := time.Minute
+	for i := len(parts) - 2; i >= 0; i-- {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return 0, err
+		}
+		total += time.Duration(n) * unit
+		unit *= 60
+	}
+	return total + time.Duration(days)*24*time.Hour, nil
+}
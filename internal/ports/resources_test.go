@@ -0,0 +1,54 @@
+package ports
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProcStat(t *testing.T) {
+	stat := "1234 (node (dev) server) S 1 1234 1234 0 -1 4194560 5000 0 0 0 250 50 0 0 20 0 11 0 100 1000000 5000"
+	got, err := parseProcStat(stat)
+	if err != nil {
+		t.Fatalf("parseProcStat failed: %v", err)
+	}
+	if got != 3*time.Second {
+		t.Fatalf("expected 3s of CPU time, got %v", got)
+	}
+	if _, err := parseProcStat("garbage"); err == nil {
+		t.Fatalf("expected error for malformed stat")
+	}
+}
+
+func TestParseProcStartTime(t *testing.T) {
+	stat := "1234 (node) S 1 1234 1234 0 -1 4194560 5000 0 0 0 250 50 0 0 20 0 11 0 12345 1000000 5000"
+	got, err := parseProcStartTime(stat, "cpu  1 2 3\nbtime 1700000000\nprocesses 10\n")
+	if err != nil {
+		t.Fatalf("parseProcStartTime failed: %v", err)
+	}
+	want := time.Unix(1700000000, 0).Add(123450 * time.Millisecond)
+	if !got.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestParseStatmRSS(t *testing.T) {
+	got, err := parseStatmRSS("50000 2560 300 10 0 4000 0", 4096)
+	if err != nil || got != 2560*4096 {
+		t.Fatalf("unexpected rss %d (err=%v)", got, err)
+	}
+}
+
+func TestParsePsTime(t *testing.T) {
+	cases := map[string]time.Duration{
+		"0:01.50":    1500 * time.Millisecond,
+		"01:02:03":   time.Hour + 2*time.Minute + 3*time.Second,
+		"2-00:00:10": 48*time.Hour + 10*time.Second,
+		" 12:30.00 ": 12*time.Minute + 30*time.Second,
+	}
+	for in, want := range cases {
+		got, err := parsePsTime(in)
+		if err != nil || got != want {
+			t.Fatalf("parsePsTime(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+}
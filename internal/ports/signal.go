@@ -0,0 +1,79 @@
+package ports
+
+import (
+	"errors"
+	"runtime"
+)
+
+// Signal is a portable signal name. On Windows, where there are no POSIX
+// signals, SignalPID maps each one to the closest available behaviour.
+type Signal string
+
+const (
+	SignalTERM Signal = "TERM"
+	SignalINT  Signal = "INT"
+	SignalHUP  Signal = "HUP"
+	SignalQUIT Signal = "QUIT"
+	SignalKILL Signal = "KILL"
+)
+
+var ErrSignalUnsupported = errors.New("signal is not supported on this platform")
+
+// Signals lists the signals offered to users, politest first.
+func Signals() []Signal {
+	return []Signal{SignalTERM, SignalINT, SignalHUP, SignalQUIT, SignalKILL}
+}
+
+func ParseSignal(name string) (Signal, bool) {
+	for _, sig := range Signals() {
+		if string(sig) == name || "SIG"+string(sig) == name {
+			return sig, true
+		}
+	}
+	return "", false
+}
+
+// Terminates reports whether the signal is expected to end the process, as
+// opposed to e.g. reloading config or dumping threads.
+func (s Signal) Terminates() bool {
+	return s == SignalTERM || s == SignalINT || s == SignalKILL
+}
+
+// Supported reports whether SignalPID can deliver the signal here. Windows
+// has no way to ask another process to reload or dump its threads, so HUP
+// and QUIT are refused there rather than mapped onto a shutdown.
+func (s Signal) Supported() bool {
+	return runtime.GOOS != "windows" || (s != SignalHUP && s != SignalQUIT)
+}
+
+// Label is how the signal is actually delivered on this platform.
+func (s Signal) Label() string {
+	if runtime.GOOS != "windows" {
+		return "SIG" + string(s)
+	}
+	switch {
+	case !s.Supported():
+		return "SIG" + string(s) + " (unsupported)"
+	case s == SignalKILL:
+		return "taskkill /F"
+	default:
+		return "taskkill"
+	}
+}
+
+// Description explains what the signal usually does to a dev server.
+func (s Signal) Description() string {
+	switch s {
+	case SignalTERM:
+		return "polite shutdown"
+	case SignalINT:
+		return "Ctrl-C, clean shutdown for most CLIs"
+	case SignalHUP:
+		return "reload configuration"
+	case SignalQUIT:
+		return "JVM thread dump / core dump"
+	case SignalKILL:
+		return "force kill, no cleanup"
+	}
+	return ""
+}
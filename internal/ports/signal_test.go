@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	for _, name := range []string{"INT", "SIGINT"} {
+		if sig, ok := ParseSignal(name); !ok || sig != SignalINT {
+			t.Fatalf("ParseSignal(%q) = %q, %v", name, sig, ok)
+		}
+	}
+	if _, ok := ParseSignal("USR1"); ok {
+		t.Fatalf("expected unsupported signal to be rejected")
+	}
+	if SignalHUP.Terminates() || !SignalINT.Terminates() {
+		t.Fatalf("unexpected Terminates results")
+	}
+	for _, sig := range []Signal{SignalHUP, SignalQUIT} {
+		if sig.Supported() != (runtime.GOOS != "windows") {
+			t.Fatalf("%s: unexpected Supported result on %s", sig, runtime.GOOS)
+		}
+	}
+	if !SignalTERM.Supported() || !SignalKILL.Supported() {
+		t.Fatalf("expected TERM and KILL to be supported everywhere")
+	}
+}
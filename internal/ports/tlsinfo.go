@@ -0,0 +1,90 @@
+package ports
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+type CertInfo struct {
+	Subject         string    `json:"subject"`
+	Issuer          string    `json:"issuer"`
+	SANs            []string  `json:"sans"`
+	NotBefore       time.Time `json:"notBefore"`
+	NotAfter        time.Time `json:"notAfter"`
+	Verified        bool      `json:"verified"`
+	VerifyError     string    `json:"verifyError"`
+	CoversLocalhost bool      `json:"coversLocalhost"`
+}
+
+// InspectTLS performs a TLS handshake with addr and describes the leaf
+// certificate. Verification against the system pool is recorded rather than
+// enforced so self-signed dev certificates can still be inspected.
+func InspectTLS(addr string, timeout time.Duration) (CertInfo, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
+		InsecureSkipVerify: true,
+		ServerName:         "localhost",
+	})
+	if err != nil {
+		return CertInfo{}, err
+	}
+	defer conn.Close()
+
+	chain := conn.ConnectionState().PeerCertificates
+	if len(chain) == 0 {
+		return CertInfo{}, errors.New("no peer certificate")
+	}
+	leaf := chain[0]
+	info := CertInfo{
+		Subject:   leaf.Subject.String(),
+		Issuer:    leaf.Issuer.String(),
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+	}
+	info.SANs = append(info.SANs, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	info.CoversLocalhost = leaf.VerifyHostname("localhost") == nil ||
+		leaf.VerifyHostname("127.0.0.1") == nil ||
+		leaf.VerifyHostname("::1") == nil
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Intermediates: intermediates}); err != nil {
+		info.VerifyError = err.Error()
+	} else {
+		info.Verified = true
+	}
+	return info, nil
+}
+
+// Warnings lists problems worth surfacing in the port list: expiry (already
+// expired or within window), chains that do not verify and certificates that
+// do not cover localhost.
+func (c CertInfo) Warnings(now time.Time, window time.Duration) []string {
+	var out []string
+	switch {
+	case now.After(c.NotAfter):
+		out = append(out, fmt.Sprintf("TLS certificate expired on %s", c.NotAfter.Local().Format("2006-01-02")))
+	case now.Add(window).After(c.NotAfter):
+		days := int(c.NotAfter.Sub(now).Hours() / 24)
+		out = append(out, fmt.Sprintf("TLS certificate expires in %d day(s) (%s)", days, c.NotAfter.Local().Format("2006-01-02")))
+	}
+	if now.Before(c.NotBefore) {
+		out = append(out, "TLS certificate is not valid yet")
+	}
+	if !c.CoversLocalhost {
+		out = append(out, "TLS certificate does not cover localhost")
+	}
+	if !c.Verified {
+		out = append(out, "TLS certificate chain does not verify against the system pool")
+	}
+	return out
+}
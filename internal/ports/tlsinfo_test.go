@@ -0,0 +1,51 @@
+package ports
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInspectTLSSelfSignedServer(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	info, err := InspectTLS(strings.TrimPrefix(srv.URL, "https://"), time.Second)
+	if err != nil {
+		t.Fatalf("InspectTLS failed: %v", err)
+	}
+	if info.Verified {
+		t.Fatalf("expected httptest certificate not to verify against the system pool")
+	}
+	if !info.CoversLocalhost {
+		t.Fatalf("expected httptest certificate to cover loopback, SANs=%v", info.SANs)
+	}
+	if info.NotAfter.IsZero() || info.Subject == "" && info.Issuer == "" {
+		t.Fatalf("expected certificate details, got %+v", info)
+	}
+}
+
+func TestCertInfoWarnings(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	cert := CertInfo{
+		NotBefore:       now.AddDate(0, -1, 0),
+		NotAfter:        now.AddDate(0, 0, 5),
+		Verified:        true,
+		CoversLocalhost: true,
+	}
+	if got := cert.Warnings(now, 14*24*time.Hour); len(got) != 1 || !strings.Contains(got[0], "expires in 5 day") {
+		t.Fatalf("expected expiry warning, got %v", got)
+	}
+	if got := cert.Warnings(now, 24*time.Hour); len(got) != 0 {
+		t.Fatalf("expected no warnings outside the window, got %v", got)
+	}
+
+	cert.NotAfter = now.AddDate(0, 0, -1)
+	cert.CoversLocalhost = false
+	got := cert.Warnings(now, 24*time.Hour)
+	if len(got) != 2 || !strings.Contains(got[0], "expired") || !strings.Contains(got[1], "localhost") {
+		t.Fatalf("expected expired and localhost warnings, got %v", got)
+	}
+}
@@ -0,0 +1,199 @@
+package ports
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supervisors that restart a process after it is killed.
+const (
+	ManagerSystemd        = "systemd"
+	ManagerDocker         = "Docker"
+	ManagerLaunchd        = "launchd"
+	ManagerWindowsService = "Windows service"
+)
+
+// ProcessEntry is one row of the process table. TTY is the controlling
+// terminal, empty when there is none or the platform does not report it
+// (Windows).
+type ProcessEntry struct {
+	PID  int    `json:"pid"`
+	PPID int    `json:"ppid"`
+	TTY  string `json:"tty,omitempty"`
+	Name string `json:"name"`
+}
+
+// Descendants returns every process below pid in table, children before
+// grandchildren.
+func Descendants(table []ProcessEntry, pid int) []ProcessEntry {
+	children := map[int][]ProcessEntry{}
+	for _, entry := range table {
+		if entry.PID != entry.PPID {
+			children[entry.PPID] = append(children[entry.PPID], entry)
+		}
+	}
+	out := make([]ProcessEntry, 0)
+	seen := map[int]bool{pid: true}
+	queue := []int{pid}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, child := range children[next] {
+			if seen[child.PID] {
+				continue
+			}
+			seen[child.PID] = true
+			out = append(out, child)
+			queue = append(queue, child.PID)
+		}
+	}
+	return out
+}
+
+// ancestors returns the parents of pid in table, nearest first.
+func ancestors(table []ProcessEntry, pid int) []ProcessEntry {
+	byPID := make(map[int]ProcessEntry, len(table))
+	for _, entry := range table {
+		byPID[entry.PID] = entry
+	}
+	out := make([]ProcessEntry, 0)
+	seen := map[int]bool{pid: true}
+	for cur, ok := byPID[pid]; ok && !seen[cur.PPID]; cur, ok = byPID[cur.PPID] {
+		seen[cur.PPID] = true
+		parent, found := byPID[cur.PPID]
+		if !found {
+			break
+		}
+		out = append(out, parent)
+	}
+	return out
+}
+
+// ReparentedTo reports whether pid's parent has exited and the process was
+// adopted: its parent is init, a systemd user manager or launchd, or no longer
+// exists. It returns the adopting process. Only the direct parent counts; a
+// process whose launcher is still alive is not adopted.
+func ReparentedTo(table []ProcessEntry, pid int) (ProcessEntry, bool) {
+	byPID := make(map[int]ProcessEntry, len(table))
+	for _, entry := range table {
+		byPID[entry.PID] = entry
+	}
+	cur, ok := byPID[pid]
+	if !ok {
+		return ProcessEntry{}, false
+	}
+	if cur.PPID <= 1 {
+		return ProcessEntry{PID: cur.PPID, Name: "init"}, true
+	}
+	parent, found := byPID[cur.PPID]
+	if !found {
+		return ProcessEntry{PID: cur.PPID}, true
+	}
+	switch strings.ToLower(filepath.Base(parent.Name)) {
+	case "systemd", "launchd":
+		return parent, true
+	}
+	return ProcessEntry{}, false
+}
+
+// OrphanInfo explains why a listener looks like a dev server left behind by a
+// closed terminal or crashed IDE.
+type OrphanInfo struct {
+	AdoptedBy  ProcessEntry  `json:"adoptedBy"`
+	ProjectDir string        `json:"projectDir"`
+	IdleFor    time.Duration `json:"idleFor"`
+}
+
+// dirScanLimit bounds how many entries DirLastModified inspects.
+const dirScanLimit = 1000
+
+// DirLastModified returns the newest modification time of dir and its direct
+// entries, a cheap stand-in for when a project was last worked on.
+func DirLastModified(dir string) (time.Time, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	latest := info.ModTime()
+	f, err := os.Open(dir)
+	if err != nil {
+		return latest, nil
+	}
+	defer f.Close()
+	entries, _ := f.ReadDir(dirScanLimit)
+	for _, entry := range entries {
+		if fi, err := entry.Info(); err == nil && fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// ProcessManager names the supervisor that is likely to respawn pid, or ""
+// when the process appears to be started by hand. table is a ListProcesses
+// snapshot used to walk the parent chain.
+func ProcessManager(pid int, table []ProcessEntry) string {
+	if runtime.GOOS == "linux" && pid > 0 {
+		if raw, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cgroup")); err == nil {
+			if manager := managerFromCgroup(string(raw)); manager != "" {
+				return manager
+			}
+		}
+	}
+	names := make([]string, 0)
+	for _, parent := range ancestors(table, pid) {
+		names = append(names, parent.Name)
+	}
+	return managerFromAncestors(names)
+}
+
+// managerFromCgroup inspects /proc/<pid>/cgroup. Processes in a container
+// belong to a docker or containerd scope; services own a .service unit.
+// Login sessions live under user@.service or session-N.scope and do not
+// count.
+func managerFromCgroup(cgroup string) string {
+	for _, raw := range strings.Split(cgroup, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(raw), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		path := parts[2]
+		if strings.Contains(path, "docker") || strings.Contains(path, "containerd") || strings.Contains(path, "/kubepods") {
+			return ManagerDocker
+		}
+		if strings.Contains(path, "/user@") || strings.Contains(path, "/session-") {
+			continue
+		}
+		for _, segment := range strings.Split(path, "/") {
+			if strings.HasSuffix(segment, ".service") {
+				return ManagerSystemd
+			}
+		}
+	}
+	return ""
+}
+
+// managerFromAncestors recognises supervisors among the parent process names,
+// nearest first.
+func managerFromAncestors(names []string) string {
+	for _, name := range names {
+		base := strings.ToLower(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+		switch {
+		case strings.HasPrefix(base, "containerd-shim"), base == "dockerd", base == "docker-proxy", base == "com.docker.backend":
+			return ManagerDocker
+		case base == "services.exe":
+			return ManagerWindowsService
+		case base == "launchd":
+			// Everything on macOS descends from launchd; only direct
+			// children are launch agents or daemons.
+			if len(names) > 0 && names[0] == name {
+				return ManagerLaunchd
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package ports
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDescendants(t *testing.T) {
+	table := []ProcessEntry{
+		{PID: 1, PPID: 0, Name: "init"},
+		{PID: 10, PPID: 1, Name: "npm"},
+		{PID: 11, PPID: 10, Name: "node"},
+		{PID: 12, PPID: 11, Name: "esbuild"},
+		{PID: 20, PPID: 1, Name: "other"},
+	}
+	got := Descendants(table, 10)
+	if len(got) != 2 || got[0].PID != 11 || got[1].PID != 12 {
+		t.Fatalf("unexpected descendants %+v", got)
+	}
+}
+
+func TestManagerFromCgroup(t *testing.T) {
+	cases := map[string]string{
+		"0::/system.slice/nginx.service\n":                                     ManagerSystemd,
+		"0::/system.slice/docker-0123abcd.scope\n":                             ManagerDocker,
+		"12:pids:/docker/0123abcd\n":                                           ManagerDocker,
+		"0::/user.slice/user-1000.slice/user@1000.service/app.slice/vte.scope": "",
+		"0::/user.slice/user-1000.slice/session-2.scope\n":                     "",
+	}
+	for cgroup, want := range cases {
+		if got := managerFromCgroup(cgroup); got != want {
+			t.Fatalf("managerFromCgroup(%q) = %q, want %q", cgroup, got, want)
+		}
+	}
+}
+
+func TestManagerFromAncestors(t *testing.T) {
+	if got := managerFromAncestors([]string{"containerd-shim-runc-v2", "systemd"}); got != ManagerDocker {
+		t.Fatalf("expected Docker, got %q", got)
+	}
+	if got := managerFromAncestors([]string{`C:\Windows\System32\services.exe`}); got != ManagerWindowsService {
+		t.Fatalf("expected Windows service, got %q", got)
+	}
+	if got := managerFromAncestors([]string{"launchd"}); got != ManagerLaunchd {
+		t.Fatalf("expected launchd, got %q", got)
+	}
+	if got := managerFromAncestors([]string{"zsh", "Terminal", "launchd"}); got != "" {
+		t.Fatalf("expected no manager for a shell child, got %q", got)
+	}
+}
+
+func TestReparentedTo(t *testing.T) {
+	table := []ProcessEntry{
+		{PID: 1, PPID: 0, Name: "systemd"},
+		{PID: 900, PPID: 1, Name: "systemd"},
+		{PID: 1000, PPID: 900, Name: "npm"},
+		{PID: 1001, PPID: 1000, Name: "node"},
+		{PID: 1101, PPID: 900, Name: "node"},
+		{PID: 1201, PPID: 1, Name: "node"},
+		{PID: 2000, PPID: 1999, TTY: "pts/3", Name: "zsh"},
+		{PID: 2001, PPID: 2000, Name: "vite"},
+		{PID: 3001, PPID: 3000, Name: "python3"},
+	}
+	if _, ok := ReparentedTo(table, 1001); ok {
+		t.Fatalf("expected a child of a running npm not to be reparented")
+	}
+	if parent, ok := ReparentedTo(table, 1101); !ok || parent.PID != 900 {
+		t.Fatalf("expected node to be adopted by the user manager, got %+v %v", parent, ok)
+	}
+	if parent, ok := ReparentedTo(table, 1201); !ok || parent.PID != 1 {
+		t.Fatalf("expected node to be adopted by init, got %+v %v", parent, ok)
+	}
+	if _, ok := ReparentedTo(table, 2001); ok {
+		t.Fatalf("expected a child of a terminal shell not to be reparented")
+	}
+	if parent, ok := ReparentedTo(table, 3001); !ok || parent.PID != 3000 {
+		t.Fatalf("expected a vanished parent to count, got %+v %v", parent, ok)
+	}
+}
+
+func TestDirLastModified(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-72 * time.Hour)
+	recent := time.Now().Add(-time.Hour).Truncate(time.Second)
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, recent, recent); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(dir, old, old); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DirLastModified(dir)
+	if err != nil || !got.Equal(recent) {
+		t.Fatalf("expected %s, got %s (%v)", recent, got, err)
+	}
+}
@@ -0,0 +1,150 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Conditions WaitForPort can wait for. WaitHealthy means in use and passing a
+// health probe.
+const (
+	WaitInUse   = "in-use"
+	WaitFree    = "free"
+	WaitHealthy = "healthy"
+)
+
+const (
+	defaultWaitInitialDelay = 100 * time.Millisecond
+	defaultWaitMaxDelay     = 5 * time.Second
+)
+
+// ErrWaitTimeout is returned, wrapped, when the condition did not hold before
+// the timeout.
+var ErrWaitTimeout = errors.New("timed out")
+
+// WaitOptions configures WaitForPort. Only Until is required.
+type WaitOptions struct {
+	Until string
+	// Timeout bounds the wait; zero waits until ctx is done.
+	Timeout time.Duration
+	// Probe is used for WaitHealthy; nil means a plain TCP connect.
+	Probe *HealthProbe
+	// The delay between checks starts at InitialDelay and doubles up to
+	// MaxDelay.
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// Scan replaces ScanPort, e.g. to reuse a test harness's scanner.
+	Scan func(port int) (PortScanResult, error)
+	// Progress, when set, is called after every check that did not succeed.
+	Progress func(WaitAttempt)
+}
+
+// WaitAttempt describes one unsuccessful check.
+type WaitAttempt struct {
+	Port    int
+	Attempt int
+	Elapsed time.Duration
+	Result  PortScanResult
+	Health  *HealthResult
+	Err     error
+	Next    time.Duration
+}
+
+func (a WaitAttempt) String() string {
+	state := string(a.Result.Status)
+	switch {
+	case a.Err != nil:
+		state = "scan failed: " + a.Err.Error()
+	case a.Health != nil:
+		state = fmt.Sprintf("%s, %s", a.Health.Status, a.Health.Detail)
+	}
+	return fmt.Sprintf("port %d: attempt %d after %s (%s); retrying in %s",
+		a.Port, a.Attempt, a.Elapsed.Round(100*time.Millisecond), state, a.Next.Round(10*time.Millisecond))
+}
+
+// WaitForPort checks port with exponential backoff until the Until condition
+// holds, and returns the result that satisfied it. Scan errors are retried;
+// if the wait ends while the last scan failed, that error is returned along
+// with ErrWaitTimeout.
+func WaitForPort(ctx context.Context, port int, opts WaitOptions) (PortScanResult, error) {
+	switch opts.Until {
+	case WaitInUse, WaitFree, WaitHealthy:
+	default:
+		return PortScanResult{}, fmt.Errorf("unknown wait condition %q", opts.Until)
+	}
+	if opts.Probe != nil {
+		if err := opts.Probe.Validate(); err != nil {
+			return PortScanResult{}, err
+		}
+	}
+	scan := opts.Scan
+	if scan == nil {
+		scan = ScanPort
+	}
+	delay := opts.InitialDelay
+	if delay <= 0 {
+		delay = defaultWaitInitialDelay
+	}
+	maxDelay := opts.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultWaitMaxDelay
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		res, err := scan(port)
+		var health *HealthResult
+		if err == nil && waitSatisfied(&res, opts, &health) {
+			return res, nil
+		}
+		if opts.Progress != nil {
+			opts.Progress(WaitAttempt{Port: port, Attempt: attempt, Elapsed: time.Since(start), Result: res, Health: health, Err: err, Next: delay})
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			cause := ctx.Err()
+			if errors.Is(cause, context.DeadlineExceeded) {
+				cause = ErrWaitTimeout
+			}
+			if err != nil {
+				return res, fmt.Errorf("port %d not %s: %w (last scan: %w)", port, opts.Until, cause, err)
+			}
+			return res, fmt.Errorf("port %d not %s: %w", port, opts.Until, cause)
+		case <-timer.C:
+		}
+		delay *= 2
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+}
+
+func waitSatisfied(res *PortScanResult, opts WaitOptions, health **HealthResult) bool {
+	switch opts.Until {
+	case WaitFree:
+		return res.Status == StatusFree
+	case WaitInUse:
+		return res.Status == StatusInUse
+	}
+	if res.Status != StatusInUse {
+		return false
+	}
+	probe := HealthProbe{Type: ProbeTCP}
+	if opts.Probe != nil {
+		probe = *opts.Probe
+	}
+	result := RunHealthProbe(DialAddress(res.LocalAddress, res.Port), res.Port, probe)
+	res.Health = &result
+	*health = &result
+	return result.Status == HealthHealthy
+}
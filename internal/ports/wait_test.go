@@ -0,0 +1,84 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWaitForPortBacksOffUntilInUse(t *testing.T) {
+	calls := 0
+	attempts := make([]WaitAttempt, 0)
+	res, err := WaitForPort(context.Background(), 5432, WaitOptions{
+		Until:        WaitInUse,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     3 * time.Millisecond,
+		Scan: func(port int) (PortScanResult, error) {
+			calls++
+			switch {
+			case calls == 2:
+				return PortScanResult{}, errors.New("lsof hiccup")
+			case calls < 5:
+				return PortScanResult{Port: port, Status: StatusFree}, nil
+			}
+			return PortScanResult{Port: port, Status: StatusInUse, PID: 9}, nil
+		},
+		Progress: func(a WaitAttempt) { attempts = append(attempts, a) },
+	})
+	if err != nil || res.PID != 9 {
+		t.Fatalf("expected the in-use result, got %+v, %v", res, err)
+	}
+	if len(attempts) != 4 || attempts[1].Err == nil {
+		t.Fatalf("expected four reported attempts with the scan error, got %+v", attempts)
+	}
+	wantDelays := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond, 3 * time.Millisecond}
+	for i, a := range attempts {
+		if a.Next != wantDelays[i] {
+			t.Fatalf("attempt %d: expected delay %s, got %s", i+1, wantDelays[i], a.Next)
+		}
+	}
+}
+
+func TestWaitForPortTimesOut(t *testing.T) {
+	_, err := WaitForPort(context.Background(), 3000, WaitOptions{
+		Until:        WaitFree,
+		Timeout:      20 * time.Millisecond,
+		InitialDelay: 5 * time.Millisecond,
+		Scan: func(port int) (PortScanResult, error) {
+			return PortScanResult{Port: port, Status: StatusInUse}, nil
+		},
+	})
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	if _, err := WaitForPort(context.Background(), 3000, WaitOptions{Until: "open"}); err == nil {
+		t.Fatal("expected an unknown condition to be rejected")
+	}
+}
+
+func TestWaitForPortHealthy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+	scan := func(p int) (PortScanResult, error) {
+		return PortScanResult{Port: p, Status: StatusInUse, LocalAddress: "127.0.0.1:" + strconv.Itoa(p)}, nil
+	}
+
+	res, err := WaitForPort(context.Background(), port, WaitOptions{Until: WaitHealthy, Timeout: time.Second, Scan: scan})
+	if err != nil || res.Health == nil || res.Health.Status != HealthHealthy {
+		t.Fatalf("expected a healthy result, got %+v, %v", res.Health, err)
+	}
+
+	probe := &HealthProbe{Type: ProbeCommand, Command: []string{"false"}}
+	_, err = WaitForPort(context.Background(), port, WaitOptions{Until: WaitHealthy, Timeout: 20 * time.Millisecond, InitialDelay: 5 * time.Millisecond, Probe: probe, Scan: scan})
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected a failing probe to time out, got %v", err)
+	}
+}
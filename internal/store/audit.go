@@ -0,0 +1,159 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"port_sentinel/internal/ports"
+)
+
+const (
+	AuditOK      = "ok"
+	AuditFailed  = "failed"
+	AuditRefused = "refused"
+	// AuditDryRun records what a remediation policy would have done.
+	AuditDryRun = "dry-run"
+)
+
+const (
+	AuditActionSignal      = "signal"
+	AuditActionListSockets = "list-sockets"
+	AuditActionNotify      = "notify"
+	AuditActionCommand     = "command"
+)
+
+// Audit log rotation: once audit.jsonl exceeds auditMaxBytes it is renamed to
+// audit.1.jsonl (shifting older files up) and at most auditKeep rotated files
+// are kept.
+const (
+	auditMaxBytes = 1 << 20
+	auditKeep     = 3
+)
+
+// AuditEntry records one terminate attempt. CommandLine is masked before it
+// is written.
+type AuditEntry struct {
+	Time        time.Time    `json:"time"`
+	User        string       `json:"user"`
+	Source      string       `json:"source"`
+	Port        int          `json:"port"`
+	PID         int          `json:"pid"`
+	ProcessName string       `json:"processName"`
+	CommandLine string       `json:"commandLine"`
+	Signal      ports.Signal `json:"signal"`
+	Outcome     string       `json:"outcome"`
+	Error       string       `json:"error,omitempty"`
+	PortFreed   bool         `json:"portFreed"`
+	Elevated    bool         `json:"elevated,omitempty"`
+	// Action is one of the AuditAction* constants; empty means
+	// AuditActionSignal, for older entries.
+	Action string `json:"action,omitempty"`
+	// Policy names the remediation policy that triggered the entry.
+	Policy string   `json:"policy,omitempty"`
+	Steps  []string `json:"steps,omitempty"`
+}
+
+func AuditPath() (string, error) {
+	return dataFilePath("audit.jsonl")
+}
+
+func AppendAudit(entry AuditEntry) error {
+	path, err := AuditPath()
+	if err != nil {
+		return err
+	}
+	return appendAuditFile(path, entry, auditMaxBytes, auditKeep)
+}
+
+// LoadAudit returns all retained entries, oldest first.
+func LoadAudit() ([]AuditEntry, error) {
+	path, err := AuditPath()
+	if err != nil {
+		return nil, err
+	}
+	return loadAuditFiles(path, auditKeep)
+}
+
+func rotatedAuditPath(path string, n int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(path, ext), n, ext)
+}
+
+func appendAuditFile(path string, entry AuditEntry, maxBytes int64, keep int) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() >= maxBytes {
+		if err := rotateAudit(path, keep); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func rotateAudit(path string, keep int) error {
+	if err := os.Remove(rotatedAuditPath(path, keep)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for n := keep - 1; n >= 1; n-- {
+		if err := os.Rename(rotatedAuditPath(path, n), rotatedAuditPath(path, n+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if keep < 1 {
+		return os.Remove(path)
+	}
+	return os.Rename(path, rotatedAuditPath(path, 1))
+}
+
+func loadAuditFiles(path string, keep int) ([]AuditEntry, error) {
+	files := make([]string, 0, keep+1)
+	for n := keep; n >= 1; n-- {
+		files = append(files, rotatedAuditPath(path, n))
+	}
+	files = append(files, path)
+
+	out := make([]AuditEntry, 0)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return out, err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1<<20)
+		for scanner.Scan() {
+			var entry AuditEntry
+			// Skip lines that are not valid JSON, e.g. a partial write.
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+				out = append(out, entry)
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
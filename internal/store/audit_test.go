@@ -0,0 +1,28 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditAppendRotatesAndLoadsInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for pid := 1; pid <= 6; pid++ {
+		// A tiny limit forces a rotation before every append after the first.
+		if err := appendAuditFile(path, AuditEntry{PID: pid, Outcome: AuditOK}, 10, 2); err != nil {
+			t.Fatalf("append %d failed: %v", pid, err)
+		}
+	}
+	if _, err := os.Stat(rotatedAuditPath(path, 3)); !os.IsNotExist(err) {
+		t.Fatalf("expected only 2 rotated files to be kept, stat err=%v", err)
+	}
+
+	entries, err := loadAuditFiles(path, 2)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].PID != 4 || entries[2].PID != 6 {
+		t.Fatalf("expected the last three entries oldest first, got %+v", entries)
+	}
+}
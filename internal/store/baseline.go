@@ -0,0 +1,61 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+var ErrNoBaseline = errors.New("no listener baseline recorded")
+
+// ListenerRecord is one listening socket. Baselines written before Address
+// and PID were recorded leave them empty.
+type ListenerRecord struct {
+	Port    int    `json:"port"`
+	Address string `json:"address,omitempty"`
+	PID     int    `json:"pid,omitempty"`
+	ExePath string `json:"exePath"`
+	User    string `json:"user"`
+}
+
+type Baseline struct {
+	CreatedAt time.Time        `json:"createdAt"`
+	Listeners []ListenerRecord `json:"listeners"`
+}
+
+func BaselinePath() (string, error) {
+	return dataFilePath("baseline.json")
+}
+
+// LoadBaseline returns ErrNoBaseline when no snapshot has been taken yet.
+func LoadBaseline() (Baseline, error) {
+	path, err := BaselinePath()
+	if err != nil {
+		return Baseline{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Baseline{}, ErrNoBaseline
+		}
+		return Baseline{}, err
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return Baseline{}, err
+	}
+	return baseline, nil
+}
+
+func SaveBaseline(baseline Baseline) error {
+	path, err := BaselinePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, "baseline-*.tmp", data)
+}
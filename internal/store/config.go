@@ -14,34 +14,314 @@ type UIConfig struct {
 	AutoRefreshEnabled    bool `json:"autoRefreshEnabled"`
 	AutoRefreshIntervalMs int  `json:"autoRefreshIntervalMs"`
 	ForceKillEnabled      bool `json:"forceKillEnabled"`
+	KillGracePeriodMs     int  `json:"killGracePeriodMs"`
+	KillEscalate          bool `json:"killEscalate"`
+	// LastSignals remembers the signal last sent per classification label.
+	LastSignals map[string]ports.Signal `json:"lastSignals"`
+}
+
+const (
+	MatchProcessName = "name"
+	MatchExePath     = "exe"
+	MatchCommandLine = "cmdline"
+)
+
+// ClassificationRule maps a process to a friendly label. Field selects what
+// Pattern is matched against (name, exe or cmdline). Globs must match the
+// whole value case-insensitively; regexes are unanchored. First match wins.
+type ClassificationRule struct {
+	Field   string `json:"field"`
+	Pattern string `json:"pattern"`
+	Regex   bool   `json:"regex"`
+	ports.Classification
+}
+
+const (
+	KillProtect = "protect"
+	KillForce   = "force"
+	KillAllow   = "allow"
+)
+
+// KillRule restricts or adjusts termination. Port and Pattern are both
+// optional; when both are set both must match. Action is KillProtect (never
+// terminate), KillForce (always send SIGKILL) or KillAllow (exempt from the
+// root/other-user protections).
+type KillRule struct {
+	Port    int    `json:"port,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Regex   bool   `json:"regex,omitempty"`
+	Action  string `json:"action"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// KillPolicyConfig guards KillProcess. PID 1 is always protected.
+type KillPolicyConfig struct {
+	ProtectRoot       bool       `json:"protectRoot"`
+	ProtectOtherUsers bool       `json:"protectOtherUsers"`
+	Rules             []KillRule `json:"rules"`
+}
+
+// ElevationConfig enables retrying permission-denied operations through an
+// OS elevation helper (pkexec, sudo -n, osascript or UAC). Method is one of
+// the ports.Elevate* constants.
+type ElevationConfig struct {
+	Enabled bool   `json:"enabled"`
+	Method  string `json:"method"`
+}
+
+// FingerprintConfig controls the opt-in active probing of listening ports to
+// detect which protocol actually answers.
+type FingerprintConfig struct {
+	Enabled   bool `json:"enabled"`
+	TimeoutMs int  `json:"timeoutMs"`
+}
+
+// TLSInspectionConfig controls certificate inspection. Ports lists ports that
+// are always inspected; ports fingerprinted as TLS or probed over https are
+// inspected too.
+type TLSInspectionConfig struct {
+	Enabled        bool  `json:"enabled"`
+	Ports          []int `json:"ports"`
+	ExpiryWarnDays int   `json:"expiryWarnDays"`
+}
+
+// IntrospectionConfig controls querying dev endpoints such as the Node
+// inspector's /json/list or Spring Boot's /actuator/info.
+type IntrospectionConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// IntegrityConfig enables hashing the executable behind each watched port so
+// unexpected binary changes can be flagged.
+type IntegrityConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ResourceConfig controls CPU/RSS sampling. Rows are highlighted once a
+// process's RSS exceeds MemoryWarnGB; 0 turns the highlight off. Sampling is
+// opt-in because outside Linux it runs ps or wmic once per process on every
+// refresh.
+type ResourceConfig struct {
+	Enabled      bool    `json:"enabled"`
+	MemoryWarnGB float64 `json:"memoryWarnGB"`
+}
+
+const (
+	RemediateNotify  = "notify"
+	RemediateSignal  = "signal"
+	RemediateCommand = "command"
+)
+
+// RemediationMatch selects the listeners a policy acts on. Zero/empty fields
+// match anything, but a policy needs at least a port or a pattern. Negate
+// inverts the pattern, e.g. "5432 held by anything but postgres*".
+// CwdUnder accepts a leading ~. MinHeldSec requires the match to persist
+// across refreshes for that long before acting.
+type RemediationMatch struct {
+	Port       int    `json:"port,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Pattern    string `json:"pattern,omitempty"`
+	Regex      bool   `json:"regex,omitempty"`
+	Negate     bool   `json:"negate,omitempty"`
+	CwdUnder   string `json:"cwdUnder,omitempty"`
+	ParentGone bool   `json:"parentGone,omitempty"`
+	MinHeldSec int    `json:"minHeldSec,omitempty"`
+}
+
+// RemediationAction is one step of a policy. Signal is used by
+// RemediateSignal; Command by RemediateCommand, whose arguments may use
+// {port}, {pid} and {name}; Message optionally replaces the notification
+// text.
+type RemediationAction struct {
+	Type    string       `json:"type"`
+	Signal  ports.Signal `json:"signal,omitempty"`
+	Command []string     `json:"command,omitempty"`
+	Message string       `json:"message,omitempty"`
+}
+
+type RemediationPolicy struct {
+	Name    string              `json:"name"`
+	Enabled bool                `json:"enabled"`
+	DryRun  bool                `json:"dryRun"`
+	Match   RemediationMatch    `json:"match"`
+	Actions []RemediationAction `json:"actions"`
+}
+
+// RemediationConfig holds the policies evaluated after each refresh. With
+// DryRun set no policy acts; would-be actions are only written to the audit
+// log.
+type RemediationConfig struct {
+	DryRun   bool                `json:"dryRun"`
+	Policies []RemediationPolicy `json:"policies"`
+}
+
+// OrphanConfig controls flagging listeners that look like leftover dev
+// servers: adopted by init or the user session manager, without a terminal,
+// and running from a project directory untouched for IdleHours.
+type OrphanConfig struct {
+	Enabled   bool `json:"enabled"`
+	IdleHours int  `json:"idleHours"`
+}
+
+// ListenerRule allowlists listeners in baseline mode. Zero/empty fields match
+// anything; Exe is a case-insensitive glob on the executable path.
+type ListenerRule struct {
+	Port int    `json:"port"`
+	Exe  string `json:"exe"`
+	User string `json:"user"`
+}
+
+// BaselineConfig enables alerting on listening sockets that are neither in
+// the recorded baseline nor allowlisted.
+type BaselineConfig struct {
+	Enabled   bool           `json:"enabled"`
+	Allowlist []ListenerRule `json:"allowlist"`
 }
 
 type Config struct {
-	PresetPorts map[int]bool `json:"presetPorts"`
-	CustomPorts []int        `json:"customPorts"`
-	PinnedPorts map[int]bool `json:"pinnedPorts"`
-	UI          UIConfig     `json:"ui"`
+	PresetPorts         map[int]bool              `json:"presetPorts"`
+	CustomPorts         []int                     `json:"customPorts"`
+	PinnedPorts         map[int]bool              `json:"pinnedPorts"`
+	ClassificationRules []ClassificationRule      `json:"classificationRules"`
+	Fingerprint         FingerprintConfig         `json:"fingerprint"`
+	HealthProbes        map[int]ports.HealthProbe `json:"healthProbes"`
+	TLSInspection       TLSInspectionConfig       `json:"tlsInspection"`
+	Introspection       IntrospectionConfig       `json:"introspection"`
+	Integrity           IntegrityConfig           `json:"integrity"`
+	Baseline            BaselineConfig            `json:"baseline"`
+	InterestingEnv      []string                  `json:"interestingEnv"`
+	Resources           ResourceConfig            `json:"resources"`
+	KillPolicy          KillPolicyConfig          `json:"killPolicy"`
+	Elevation           ElevationConfig           `json:"elevation"`
+	Remediation         RemediationConfig         `json:"remediation"`
+	Orphans             OrphanConfig              `json:"orphans"`
+	UI                  UIConfig                  `json:"ui"`
 }
 
 func DefaultConfig() Config {
 	return Config{
-		PresetPorts: ports.DefaultPresetPorts(),
-		CustomPorts: []int{},
-		PinnedPorts: map[int]bool{},
+		PresetPorts:         ports.DefaultPresetPorts(),
+		CustomPorts:         []int{},
+		PinnedPorts:         map[int]bool{},
+		ClassificationRules: DefaultClassificationRules(),
+		Fingerprint: FingerprintConfig{
+			Enabled:   false,
+			TimeoutMs: 800,
+		},
+		HealthProbes: map[int]ports.HealthProbe{},
+		TLSInspection: TLSInspectionConfig{
+			Enabled:        false,
+			Ports:          []int{443, 8443},
+			ExpiryWarnDays: 14,
+		},
+		InterestingEnv: DefaultInterestingEnv(),
+		Resources: ResourceConfig{
+			Enabled:      false,
+			MemoryWarnGB: 2,
+		},
+		KillPolicy: KillPolicyConfig{
+			ProtectRoot:       true,
+			ProtectOtherUsers: true,
+			Rules:             DefaultKillRules(),
+		},
+		Elevation: ElevationConfig{
+			Enabled: false,
+			Method:  ports.ElevateAuto,
+		},
+		Remediation: RemediationConfig{
+			Policies: []RemediationPolicy{},
+		},
+		Orphans: OrphanConfig{
+			Enabled:   false,
+			IdleHours: 24,
+		},
 		UI: UIConfig{
 			AutoRefreshEnabled:    false,
 			AutoRefreshIntervalMs: 5000,
 			ForceKillEnabled:      false,
+			KillGracePeriodMs:     5000,
+			KillEscalate:          true,
+			LastSignals:           map[string]ports.Signal{},
 		},
 	}
 }
 
+// DefaultInterestingEnv lists variables that commonly decide which port a dev
+// server binds to.
+func DefaultInterestingEnv() []string {
+	return []string{
+		"PORT", "HOST", "HOSTNAME", "BIND", "LISTEN_ADDR",
+		"NODE_ENV", "VITE_PORT", "NEXT_PUBLIC_PORT",
+		"SPRING_PROFILES_ACTIVE", "SERVER_PORT",
+		"DJANGO_SETTINGS_MODULE", "FLASK_RUN_PORT", "FLASK_ENV", "RAILS_ENV",
+		"DATABASE_URL", "REDIS_URL",
+	}
+}
+
+func DefaultKillRules() []KillRule {
+	protect := func(pattern, reason string) KillRule {
+		return KillRule{Field: MatchProcessName, Pattern: pattern, Action: KillProtect, Reason: reason}
+	}
+	return []KillRule{
+		protect("sshd", "remote access daemon"),
+		protect("dockerd", "Docker daemon"),
+		protect("containerd", "container runtime"),
+		protect("com.docker.backend", "Docker Desktop"),
+		protect("systemd*", "init system"),
+		protect("launchd", "init system"),
+		protect("svchost", "Windows service host"),
+	}
+}
+
+func DefaultClassificationRules() []ClassificationRule {
+	rule := func(field, pattern string, regex bool, label, category, icon, color string) ClassificationRule {
+		return ClassificationRule{
+			Field:   field,
+			Pattern: pattern,
+			Regex:   regex,
+			Classification: ports.Classification{
+				Label:    label,
+				Category: category,
+				Icon:     icon,
+				Color:    color,
+			},
+		}
+	}
+	return []ClassificationRule{
+		rule(MatchCommandLine, `(^|[\\/\s])next(-server| dev| start)`, true, "Next.js dev", "web", "▲", "#111111"),
+		rule(MatchCommandLine, `(^|[\\/\s])vite(\.js)?(\s|$)`, true, "Vite dev", "web", "⚡", "#646CFF"),
+		rule(MatchCommandLine, "*react-scripts*start*", false, "Create React App", "web", "⚛", "#61DAFB"),
+		rule(MatchCommandLine, "*ng serve*", false, "Angular dev", "web", "🅰", "#DD0031"),
+		rule(MatchCommandLine, "*webpack*serve*", false, "webpack dev server", "web", "📦", "#8DD6F9"),
+		rule(MatchCommandLine, "*nuxt*", false, "Nuxt dev", "web", "⛰", "#00DC82"),
+		rule(MatchCommandLine, `org\.springframework\.boot|spring-boot`, true, "Spring Boot", "jvm", "🌱", "#6DB33F"),
+		rule(MatchCommandLine, "*jupyter*", false, "Jupyter", "notebook", "📓", "#F37626"),
+		rule(MatchCommandLine, "*manage.py runserver*", false, "Django dev", "web", "🐍", "#0C4B33"),
+		rule(MatchCommandLine, "*flask*run*", false, "Flask dev", "web", "🐍", "#3B8EA5"),
+		rule(MatchCommandLine, `(^|[\\/\s])(uvicorn|gunicorn|hypercorn)(\s|$)`, true, "Python web server", "web", "🐍", "#3776AB"),
+		rule(MatchCommandLine, `rails (s|server)(\s|$)|(^|[\\/\s])puma(\s|$)`, true, "Rails", "web", "💎", "#CC0000"),
+		rule(MatchCommandLine, "*rabbitmq*", false, "RabbitMQ", "broker", "🐇", "#FF6600"),
+		rule(MatchProcessName, "postgres*", false, "PostgreSQL", "database", "🐘", "#336791"),
+		rule(MatchProcessName, "redis-server", false, "Redis", "database", "🟥", "#DC382D"),
+		rule(MatchProcessName, "mongod", false, "MongoDB", "database", "🍃", "#47A248"),
+		rule(MatchProcessName, `^(mysqld|mariadbd)$`, true, "MySQL", "database", "🐬", "#4479A1"),
+		rule(MatchProcessName, "memcached", false, "Memcached", "cache", "🧠", "#7A7A7A"),
+		rule(MatchProcessName, "docker-proxy", false, "Docker port", "container", "🐳", "#2496ED"),
+		rule(MatchProcessName, "com.docker.*", false, "Docker Desktop", "container", "🐳", "#2496ED"),
+	}
+}
+
 func ConfigPath() (string, error) {
+	return dataFilePath("config.json")
+}
+
+func dataFilePath(name string) (string, error) {
 	dir, err := os.UserConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, "portsentinel", "config.json"), nil
+	return filepath.Join(dir, "portsentinel", name), nil
 }
 
 func LoadConfig() (Config, error) {
@@ -69,26 +349,118 @@ func LoadConfig() (Config, error) {
 	if cfg.PinnedPorts == nil {
 		cfg.PinnedPorts = map[int]bool{}
 	}
-	if cfg.UI.AutoRefreshIntervalMs == 0 {
-		cfg.UI.AutoRefreshIntervalMs = 5000
+	if cfg.ClassificationRules == nil {
+		cfg.ClassificationRules = DefaultClassificationRules()
 	}
+	if cfg.HealthProbes == nil {
+		cfg.HealthProbes = map[int]ports.HealthProbe{}
+	}
+	if cfg.TLSInspection.Ports == nil {
+		cfg.TLSInspection.Ports = []int{443, 8443}
+	}
+	if cfg.TLSInspection.ExpiryWarnDays == 0 {
+		cfg.TLSInspection.ExpiryWarnDays = 14
+	}
+	if cfg.InterestingEnv == nil {
+		cfg.InterestingEnv = DefaultInterestingEnv()
+	}
+	applyKillPolicyDefaults(&cfg.KillPolicy, data)
+	if cfg.Elevation.Method == "" {
+		cfg.Elevation.Method = ports.ElevateAuto
+	}
+	if cfg.Orphans.IdleHours == 0 {
+		cfg.Orphans.IdleHours = 24
+	}
+	if cfg.Remediation.Policies == nil {
+		cfg.Remediation.Policies = []RemediationPolicy{}
+	}
+	applyResourceDefaults(&cfg.Resources, data)
+	if cfg.Fingerprint.TimeoutMs == 0 {
+		cfg.Fingerprint.TimeoutMs = 800
+	}
+	if cfg.UI.LastSignals == nil {
+		cfg.UI.LastSignals = map[string]ports.Signal{}
+	}
+	applyUIDefaults(&cfg.UI, data)
 	return cfg, nil
 }
 
+// applyResourceDefaults sets the memory limit only when its key is missing
+// from data, so a saved 0 keeps the highlight off.
+func applyResourceDefaults(resources *ResourceConfig, data []byte) {
+	var present struct {
+		Resources struct {
+			MemoryWarnGB *float64 `json:"memoryWarnGB"`
+		} `json:"resources"`
+	}
+	_ = json.Unmarshal(data, &present)
+	if present.Resources.MemoryWarnGB == nil {
+		resources.MemoryWarnGB = 2
+	}
+}
+
+// applyUIDefaults fills in the UI timings and turns kill escalation on only
+// when its key is missing from data, so a saved false survives.
+func applyUIDefaults(ui *UIConfig, data []byte) {
+	var present struct {
+		UI struct {
+			KillEscalate *bool `json:"killEscalate"`
+		} `json:"ui"`
+	}
+	_ = json.Unmarshal(data, &present)
+	if present.UI.KillEscalate == nil {
+		ui.KillEscalate = true
+	}
+	if ui.KillGracePeriodMs == 0 {
+		ui.KillGracePeriodMs = 5000
+	}
+	if ui.AutoRefreshIntervalMs == 0 {
+		ui.AutoRefreshIntervalMs = 5000
+	}
+}
+
+// applyKillPolicyDefaults turns the protections on only when their keys are
+// missing from data, so a saved false survives, and restores the default
+// rules only when the rules key is missing.
+func applyKillPolicyDefaults(policy *KillPolicyConfig, data []byte) {
+	var present struct {
+		KillPolicy struct {
+			ProtectRoot       *bool `json:"protectRoot"`
+			ProtectOtherUsers *bool `json:"protectOtherUsers"`
+		} `json:"killPolicy"`
+	}
+	_ = json.Unmarshal(data, &present)
+	if present.KillPolicy.ProtectRoot == nil {
+		policy.ProtectRoot = true
+	}
+	if present.KillPolicy.ProtectOtherUsers == nil {
+		policy.ProtectOtherUsers = true
+	}
+	if policy.Rules == nil {
+		policy.Rules = DefaultKillRules()
+	}
+}
+
 func SaveConfig(cfg Config) error {
 	path, err := ConfigPath()
 	if err != nil {
 		return err
 	}
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0o700); err != nil {
-		return err
-	}
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return err
 	}
-	tmpFile, err := os.CreateTemp(dir, "config-*.tmp")
+	return writeFileAtomic(path, "config-*.tmp", data)
+}
+
+// writeFileAtomic replaces path with data via a temp file in the same
+// directory, so a crash never leaves a half-written file behind.
+func writeFileAtomic(path, tmpPattern string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(dir, tmpPattern)
 	if err != nil {
 		return err
 	}
@@ -50,6 +50,145 @@ func TestConfigLoadSave(t *testing.T) {
 	}
 }
 
+func TestLoadConfigKeepsDisabledOrphanDetection(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("APPDATA", tmp)
+	t.Setenv("XDG_CONFIG_HOME", tmp)
+	t.Setenv("HOME", tmp)
+
+	if DefaultConfig().Orphans.Enabled {
+		t.Fatalf("expected orphan detection to be opt-in")
+	}
+	path, err := ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"orphans":{"enabled":false}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if loaded.Orphans.Enabled || loaded.Orphans.IdleHours != 24 {
+		t.Fatalf("expected detection to stay off with the default idle hours, got %+v", loaded.Orphans)
+	}
+}
+
+func TestLoadConfigKeepsKillPolicyFlags(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("APPDATA", tmp)
+	t.Setenv("XDG_CONFIG_HOME", tmp)
+	t.Setenv("HOME", tmp)
+
+	path, err := ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(content string) Config {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		return loaded
+	}
+
+	policy := write(`{"killPolicy":{"protectRoot":true,"protectOtherUsers":false}}`).KillPolicy
+	if !policy.ProtectRoot || policy.ProtectOtherUsers || len(policy.Rules) == 0 {
+		t.Fatalf("expected saved flags to survive and default rules to be filled in, got %+v", policy)
+	}
+	policy = write(`{"killPolicy":{"protectRoot":false,"protectOtherUsers":false,"rules":[]}}`).KillPolicy
+	if policy.ProtectRoot || policy.ProtectOtherUsers || len(policy.Rules) != 0 {
+		t.Fatalf("expected an emptied policy to stay empty, got %+v", policy)
+	}
+	policy = write(`{"customPorts":[4000]}`).KillPolicy
+	if !policy.ProtectRoot || !policy.ProtectOtherUsers || len(policy.Rules) == 0 {
+		t.Fatalf("expected a missing kill policy to use the defaults, got %+v", policy)
+	}
+}
+
+func TestLoadConfigDefaultsKillEscalateOnlyWhenMissing(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("APPDATA", tmp)
+	t.Setenv("XDG_CONFIG_HOME", tmp)
+	t.Setenv("HOME", tmp)
+
+	path, err := ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(content string) UIConfig {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		return loaded.UI
+	}
+
+	if ui := write(`{"ui":{"killGracePeriodMs":2000}}`); !ui.KillEscalate || ui.KillGracePeriodMs != 2000 {
+		t.Fatalf("expected escalation to default on when the key is missing, got %+v", ui)
+	}
+	if ui := write(`{"ui":{"killEscalate":false}}`); ui.KillEscalate || ui.KillGracePeriodMs != 5000 {
+		t.Fatalf("expected a saved false to survive with the default grace period, got %+v", ui)
+	}
+	if ui := write(`{"customPorts":[4000]}`); !ui.KillEscalate {
+		t.Fatalf("expected a missing ui section to use the default, got %+v", ui)
+	}
+}
+
+func TestLoadConfigKeepsDisabledMemoryLimit(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("APPDATA", tmp)
+	t.Setenv("XDG_CONFIG_HOME", tmp)
+	t.Setenv("HOME", tmp)
+
+	if DefaultConfig().Resources.Enabled {
+		t.Fatalf("expected resource sampling to be opt-in")
+	}
+	path, err := ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(content string) ResourceConfig {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		return loaded.Resources
+	}
+
+	if res := write(`{"resources":{"enabled":true,"memoryWarnGB":0}}`); !res.Enabled || res.MemoryWarnGB != 0 {
+		t.Fatalf("expected a saved 0 to keep the highlight off, got %+v", res)
+	}
+	if res := write(`{"resources":{"enabled":true}}`); res.MemoryWarnGB != 2 {
+		t.Fatalf("expected the default limit when the key is missing, got %+v", res)
+	}
+}
+
 func TestSaveConfigDoesNotLeaveTempFileAndReplacesContent(t *testing.T) {
 	tmp := t.TempDir()
 	t.Setenv("APPDATA", tmp)
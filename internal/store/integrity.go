@@ -0,0 +1,50 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// ExeRecord is the executable first seen holding a watched port.
+type ExeRecord struct {
+	ExePath   string    `json:"exePath"`
+	SHA256    string    `json:"sha256"`
+	FirstSeen time.Time `json:"firstSeen"`
+}
+
+func IntegrityPath() (string, error) {
+	return dataFilePath("integrity.json")
+}
+
+func LoadIntegrity() (map[int]ExeRecord, error) {
+	path, err := IntegrityPath()
+	if err != nil {
+		return map[int]ExeRecord{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[int]ExeRecord{}, nil
+		}
+		return map[int]ExeRecord{}, err
+	}
+	records := map[int]ExeRecord{}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return map[int]ExeRecord{}, err
+	}
+	return records, nil
+}
+
+func SaveIntegrity(records map[int]ExeRecord) error {
+	path, err := IntegrityPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, "integrity-*.tmp", data)
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+// assignmentPattern finds name=value and name: value pairs in a command
+// line; the value is masked when sensitiveKey accepts the name, so flags and
+// environment variables share one definition of a secret. Values end at ?
+// and & so query parameters are checked one by one, and a colon followed by /
+// is a URL scheme, not a pair.
+var assignmentPattern = regexp.MustCompile(`([A-Za-z0-9_.-]+)(?:\s*=\s*[^\s&?]+|\s*:\s*[^\s&?/][^\s&?]*)`)
+var bearerTokenPattern = regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`)
+var urlCredentialPattern = regexp.MustCompile(`(://[^:/@\s]+):[^@\s]+@`)
+
+// secretSegments are whole name segments that mark a secret, e.g.
+// GITHUB_TOKEN or --db-pass but not TOKENIZERS_PARALLELISM.
+var secretSegments = map[string]bool{
+	"pass": true, "pwd": true, "token": true, "tokens": true, "secret": true, "secrets": true,
+	"apikey": true, "credential": true, "credentials": true, "cookie": true, "cookies": true, "signature": true,
+}
+
+// publicKeyPrefixes are the segments before a trailing KEY that do not make
+// it a secret.
+var publicKeyPrefixes = map[string]bool{
+	"public": true, "publishable": true, "sort": true, "partition": true, "cache": true, "primary": true,
+}
+
+// visibleNames are exact names kept readable although a segment matches.
+var visibleNames = map[string]bool{"pwd": true, "oldpwd": true}
+
+func MaskSensitiveArgs(input string) string {
+	if strings.TrimSpace(input) == "" {
+		return input
+	}
+	masked := assignmentPattern.ReplaceAllStringFunc(input, func(pair string) string {
+		name := assignmentPattern.FindStringSubmatch(pair)[1]
+		if !sensitiveKey(name) {
+			return pair
+		}
+		return name + "=***"
+	})
+	masked = bearerTokenPattern.ReplaceAllString(masked, "bearer ***")
+	masked = urlCredentialPattern.ReplaceAllString(masked, "$1:***@")
+	return masked
+}
+
+// MaskEnvValue hides the value of variables whose name looks secret and
+// masks credentials embedded in the others (e.g. DATABASE_URL).
+func MaskEnvValue(key, value string) string {
+	if value == "" {
+		return value
+	}
+	if sensitiveKey(key) {
+		return "***"
+	}
+	return MaskSensitiveArgs(value)
+}
+
+// sensitiveKey matches the _, - or . separated segments of a variable or
+// flag name. Password segments may carry a prefix (PGPASSWORD), any trailing
+// KEY counts unless publicKeyPrefixes names it, and AUTH and SESSION only
+// count as the last segment, so DBUS_SESSION_BUS_ADDRESS and
+// XDG_SESSION_TYPE stay readable.
+func sensitiveKey(key string) bool {
+	lower := strings.ToLower(strings.TrimLeft(key, "-"))
+	if visibleNames[lower] {
+		return false
+	}
+	segments := strings.FieldsFunc(lower, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})
+	for _, seg := range segments {
+		if secretSegments[seg] || strings.HasSuffix(seg, "password") || strings.HasSuffix(seg, "passwd") {
+			return true
+		}
+	}
+	n := len(segments)
+	if n == 0 {
+		return false
+	}
+	switch segments[n-1] {
+	case "key":
+		return n == 1 || !publicKeyPrefixes[segments[n-2]]
+	case "auth", "session":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskSensitiveArgsMasksKnownSecrets(t *testing.T) {
+	input := "server --token=abc123 --password:letmein --api_key =k123 Authorization: Bearer qwerty.zzz"
+	out := MaskSensitiveArgs(input)
+
+	for _, secret := range []string{"abc123", "letmein", "k123", "qwerty.zzz"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("expected secret %q to be masked, got: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "token=***") {
+		t.Fatalf("expected token to be masked, got: %s", out)
+	}
+	if !strings.Contains(out, "password=***") {
+		t.Fatalf("expected password to be masked, got: %s", out)
+	}
+	if !strings.Contains(out, "api_key=***") {
+		t.Fatalf("expected api_key to be masked, got: %s", out)
+	}
+	if !strings.Contains(strings.ToLower(out), "bearer ***") {
+		t.Fatalf("expected bearer token to be masked, got: %s", out)
+	}
+}
+
+func TestMaskSensitiveArgsSharesEnvNames(t *testing.T) {
+	input := "MYSQL_PWD=s1 node app.js --db-pass=s2 --jwt-key s3 -Dspring.datasource.password=s4 --encryption_key:s5 PWD=/home/dev --sort-key=name"
+	out := MaskSensitiveArgs(input)
+	for _, secret := range []string{"s1", "s2", "s4", "s5"} {
+		if strings.Contains(out, "="+secret) || strings.Contains(out, ":"+secret) {
+			t.Fatalf("expected %q to be masked, got: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "PWD=/home/dev") || !strings.Contains(out, "--sort-key=name") {
+		t.Fatalf("expected non-secret pairs unchanged, got: %s", out)
+	}
+}
+
+func TestMaskSensitiveArgsKeepsEmptyInput(t *testing.T) {
+	if got := MaskSensitiveArgs("   "); got != "   " {
+		t.Fatalf("expected whitespace input unchanged, got %q", got)
+	}
+}
+
+func TestMaskEnvValue(t *testing.T) {
+	if got := MaskEnvValue("GITHUB_TOKEN", "ghp_abc"); got != "***" {
+		t.Fatalf("expected secret-looking key to be masked, got %q", got)
+	}
+	if got := MaskEnvValue("DATABASE_URL", "postgres://app:hunter2@db:5432/app"); got != "postgres://app:***@db:5432/app" {
+		t.Fatalf("expected URL credentials to be masked, got %q", got)
+	}
+	if got := MaskEnvValue("PORT", "3001"); got != "3001" {
+		t.Fatalf("expected plain value unchanged, got %q", got)
+	}
+	for _, key := range []string{"PGPASSWORD", "AWS_SECRET_ACCESS_KEY", "STRIPE_API_KEY", "NPM_AUTH", "GH-TOKEN",
+		"MYSQL_PWD", "DB_PASS", "SMTP_PASS", "DB_PWD", "JWT_KEY", "ENCRYPTION_KEY"} {
+		if got := MaskEnvValue(key, "value"); got != "***" {
+			t.Fatalf("expected %s to be masked, got %q", key, got)
+		}
+	}
+	for _, key := range []string{"PWD", "OLDPWD", "DBUS_SESSION_BUS_ADDRESS", "XDG_SESSION_TYPE", "AUTHOR", "TOKENIZERS_PARALLELISM", "STRIPE_PUBLISHABLE_KEY"} {
+		if got := MaskEnvValue(key, "/home/dev/src"); got != "/home/dev/src" {
+			t.Fatalf("expected %s to stay readable, got %q", key, got)
+		}
+	}
+}